@@ -10,14 +10,17 @@ import (
 	"syscall"
 	"time"
 
+	"sync-algo/internal/auth"
 	"sync-algo/internal/config"
 	algorithmController "sync-algo/internal/controller/algorithm"
+	auditController "sync-algo/internal/controller/audit"
 	clientController "sync-algo/internal/controller/client"
 	"sync-algo/internal/deployer"
 	"sync-algo/internal/lib/logger"
 	"sync-algo/internal/lib/logger/sl"
 	"sync-algo/internal/scheduler"
 	algorithmService "sync-algo/internal/service/algorithm"
+	auditService "sync-algo/internal/service/audit"
 	clientService "sync-algo/internal/service/client"
 	"sync-algo/internal/storage/postgres"
 
@@ -29,6 +32,16 @@ import (
 // @title Algo Sync Service
 // @version 1.0
 // @description Microservice from syncing user's algorythms in kubernates.
+//
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description Static API key
+//
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT as "Bearer <token>"
 func main() {
 	cfg := config.MustLoad()
 
@@ -45,24 +58,42 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Deployer initialization
+	deployer, err := newDeployer(cfg.Kubernates)
+	if err != nil {
+		log.Error(`failed to init 'deployer'`, sl.Error(err))
+		os.Exit(1)
+	}
+
 	// Service layer
-	clientService := clientService.New(storage, log)
+	clientService := clientService.New(storage, deployer, log)
 	algorithmService := algorithmService.New(storage, log)
+	auditService := auditService.New(storage, log)
 
 	// Controller layer
 	clientController := clientController.New(clientService, log)
 	algorithmController := algorithmController.New(algorithmService, log)
-
-	// Deployer initialization
-	deployer, err := deployer.New(cfg.Kubernates)
-	if err != nil {
-		log.Error(`failed to init 'deployer'`, sl.Error(err))
-		os.Exit(1)
+	auditController := auditController.New(auditService, log)
+
+	// Authentication
+	authenticators := []auth.Authenticator{auth.NewAPIKeys(storage)}
+	if cfg.Auth.JWTEnabled() {
+		jwt, err := auth.NewJWT(cfg.Auth)
+		if err != nil {
+			log.Error(`failed to init JWT authentication`, sl.Error(err))
+			os.Exit(1)
+		}
+		authenticators = append(authenticators, jwt)
 	}
 
 	// Scheduler initialization
-	sch := scheduler.New(log, storage)
-	go sch.Start(ctx, deployer)
+	sch := scheduler.New(log, cfg.Scheduler, storage)
+	schCtx, stopScheduler := context.WithCancel(ctx)
+	schDone := make(chan struct{})
+	go func() {
+		defer close(schDone)
+		sch.Start(schCtx, deployer)
+	}()
 
 	// Init router
 	r := chi.NewRouter()
@@ -72,8 +103,21 @@ func main() {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 
-	r.Route("/clients", clientController.Register())
-	r.Route("/algorithms", algorithmController.Register())
+	// API routes, the documentation stays public.
+	// Every route requires a permission of the caller's role, see the controllers
+	r.Group(func(r chi.Router) {
+		r.Use(auth.Middleware(log, authenticators...))
+		r.Use(auth.Roles(log, storage))
+
+		r.Route("/clients", func(r chi.Router) {
+			clientController.Register()(r)
+			algorithmController.RegisterClient()(r)
+		})
+		r.Route("/algorithms", algorithmController.Register())
+		r.Route("/audit", auditController.Register())
+		r.Group(clientController.RegisterBatch())
+		r.Group(algorithmController.RegisterBatch())
+	})
 
 	// Swagger documentation
 	r.Get("/docs/*", httpSwagger.Handler(
@@ -111,6 +155,10 @@ func main() {
 
 	log.Info("shutting down server...")
 
+	// Abort the sync in progress before the storage is closed
+	stopScheduler()
+	<-schDone
+
 	c, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -121,3 +169,18 @@ func main() {
 	storage.Close()
 	log.Info("server stopped")
 }
+
+// workloadDeployer is implemented by the deployers of all modes
+type workloadDeployer interface {
+	scheduler.Deployer
+	clientService.Namespaces
+}
+
+// newDeployer creates a deployer working in the configured mode
+func newDeployer(cfg *config.Kubernates) (workloadDeployer, error) {
+	if cfg.Mode == config.ModeDeployment {
+		return deployer.NewDeploymentDeployer(cfg)
+	}
+
+	return deployer.NewPodDeployer(cfg)
+}
@@ -15,9 +15,72 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/algorithm/": {
-            "patch": {
-                "description": "Update the status of algorithms based on the provided data.",
+        "/algorithms/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the algorithm catalog",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "algorithms"
+                ],
+                "summary": "List algorithms",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Algorithm"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/algorithms/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add the algorithm to the catalog or replace its default image and resources.\nDisabling the algorithm stops it for every client.",
                 "consumes": [
                     "application/json"
                 ],
@@ -27,33 +90,64 @@ const docTemplate = `{
                 "tags": [
                     "algorithms"
                 ],
-                "summary": "Update algorithm statuses",
+                "summary": "Save an algorithm",
                 "parameters": [
                     {
-                        "description": "Algorithm statuses to update",
+                        "type": "string",
+                        "description": "Algorithm name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Algorithm defaults",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.AlgoStatuses"
+                            "$ref": "#/definitions/models.Algorithm"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Updated algorithm statuses",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.AlgoStatuses"
+                            "$ref": "#/definitions/models.Algorithm"
                         }
                     },
                     "400": {
-                        "description": "Invalid credentials or data",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
                     "500": {
-                        "description": "Internal error",
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -61,9 +155,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/clients/": {
-            "post": {
-                "description": "Add a new client to the system",
+        "/algorithms:batch": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply algorithm toggles of many clients in a single transaction.\nAn atomic batch is applied all-or-nothing: if any item is invalid, none is tried,\nthe invalid items are reported as errors and the others as skipped.\nOtherwise the result of every item, including the invalid ones, is reported separately.",
                 "consumes": [
                     "application/json"
                 ],
@@ -71,82 +173,145 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "clients"
+                    "algorithms"
                 ],
-                "summary": "Add a new client",
+                "summary": "Toggle algorithms in bulk",
                 "parameters": [
                     {
-                        "description": "Client information",
-                        "name": "request",
+                        "description": "Algorithm statuses to update",
+                        "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Client"
+                            "$ref": "#/definitions/models.AlgoBatch"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Client"
+                            "$ref": "#/definitions/models.AlgoBatchResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid body or batch size",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
                     },
+                    "422": {
+                        "description": "Atomic batch with invalid items or rolled back",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlgoBatchResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
             }
         },
-        "/clients/{id}": {
-            "put": {
-                "description": "Update an existing client in the system",
-                "consumes": [
-                    "application/json"
+        "/audit/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get a page of the changes made through the API, the newest first.\nThe next page is fetched by passing next_cursor back with the same filters.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "clients"
+                    "audit"
                 ],
-                "summary": "Update an existing client",
+                "summary": "List audit events",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Client ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "name": "client_id",
+                        "in": "query"
                     },
                     {
-                        "description": "Updated client information",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Client"
-                        }
+                        "type": "string",
+                        "description": "Earliest time of the events, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest time of the events, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page size, 100 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Client"
+                            "$ref": "#/definitions/models.AuditPage"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid limit",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -156,39 +321,135 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a client from the system",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/clients/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get a page of the clients matching the filters, the next page is fetched by passing next_cursor back.\nThe cursor is valid only for the same sort order.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "clients"
                 ],
-                "summary": "Delete a client",
+                "summary": "List clients",
                 "parameters": [
                     {
+                        "type": "string",
+                        "description": "Substring of the client's name, case-insensitive",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Algorithm enabled for the client",
+                        "name": "algorithm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Whether the client waits for a restart",
+                        "name": "need_restart",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Earliest creation time, RFC 3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Latest creation time, RFC 3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Lowest priority",
+                        "name": "priority_min",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Highest priority",
+                        "name": "priority_max",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "-id",
+                            "name",
+                            "-name",
+                            "created_at",
+                            "-created_at",
+                            "priority",
+                            "-priority"
+                        ],
+                        "type": "string",
+                        "description": "Sort key, prefixed with - for the descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "minimum": 1,
                         "type": "integer",
-                        "description": "Client ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Page size, 50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/response.Response"
+                            "$ref": "#/definitions/models.ClientPage"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid filter or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid sort key or limit",
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
@@ -198,59 +459,1113 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/response.Response"
                         }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "models.AlgoStatuses": {
-            "type": "object",
-            "properties": {
-                "client_id": {
-                    "type": "integer",
-                    "example": 123
-                },
-                "hft": {
-                    "type": "boolean",
-                    "example": true
-                },
-                "twap": {
-                    "type": "boolean",
-                    "example": false
-                },
-                "vwap": {
-                    "type": "boolean",
-                    "example": true
-                }
-            }
-        },
-        "models.Client": {
-            "type": "object",
-            "properties": {
-                "client_name": {
-                    "type": "string",
-                    "example": "Client A"
-                },
-                "cpu": {
-                    "type": "string",
-                    "example": "2 cores"
-                },
-                "created_at": {
-                    "type": "string",
-                    "example": "2024-07-01T08:00:00Z"
-                },
-                "id": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "image": {
-                    "type": "string",
-                    "example": "client-image:latest"
-                },
-                "memory": {
-                    "type": "string",
-                    "example": "4 GB"
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a new client to the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Add a new client",
+                "parameters": [
+                    {
+                        "description": "Client information",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Client"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Client"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Client name already taken",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage or cluster unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/clients/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get a client by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Get a client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Client"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Revision of the client"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace all fields of an existing client, the fields left out are reset.\nThe update is applied only if the revision in If-Match, or in the body, is still current.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Update an existing client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the client the update is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Updated client information",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Client"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Client"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Revision of the updated client"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Client name already taken",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Client modified by another request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a client from the system",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Delete a client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage or cluster unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change only the client's fields present in the body, the others are kept as they are.\nThe patch is applied only if the revision in If-Match, or in the body, is still current.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Patch an existing client",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the client the patch is based on",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ClientPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Client"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Revision of the patched client"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Client name already taken",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "412": {
+                        "description": "Client modified by another request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/clients/{id}/algorithms": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get statuses of the algorithms of a client and the observed state of their workloads in the cluster",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Get client's algorithm statuses",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlgoStatuses"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable and disable the client's algorithms, the algorithms missing in the body are left as they are.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "algorithms"
+                ],
+                "summary": "Update algorithm statuses",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Algorithm statuses to update",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AlgoStatuses"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated algorithm statuses",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlgoStatuses"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid client id or body",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "No algorithms or unknown algorithm",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/clients/{id}/algorithms/{name}/params": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the parameters passed to the workload of the client's algorithm",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Get parameters of client's algorithm",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Algorithm name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlgoParams"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the parameters passed to the workload of the client's algorithm.\nOnly the workload of this algorithm is redeployed with the new parameters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Update parameters of client's algorithm",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Algorithm name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Algorithm parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AlgoParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AlgoParams"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/clients:batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    },
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply many client creations and updates in a single transaction.\nAn atomic batch is applied all-or-nothing: if any item is invalid, none is tried,\nthe invalid items are reported as errors and the others as skipped.\nOtherwise the result of every item, including the invalid ones, is reported separately.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "clients"
+                ],
+                "summary": "Create and update clients in bulk",
+                "parameters": [
+                    {
+                        "description": "Client operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ClientBatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ClientBatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or batch size",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "Role not allowed to perform the operation",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "422": {
+                        "description": "Atomic batch with invalid items or rolled back",
+                        "schema": {
+                            "$ref": "#/definitions/models.ClientBatchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Storage unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.AlgoBatch": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AlgoStatuses"
+                    }
+                }
+            }
+        },
+        "models.AlgoBatchResponse": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AlgoBatchResult"
+                    }
+                }
+            }
+        },
+        "models.AlgoBatchResult": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "client_not_found"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Client not found"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "error",
+                        "rolled_back",
+                        "skipped"
+                    ],
+                    "example": "ok"
+                },
+                "statuses": {
+                    "$ref": "#/definitions/models.AlgoStatuses"
+                }
+            }
+        },
+        "models.AlgoParams": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string",
+                    "example": "twap"
+                },
+                "client_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "params": {
+                    "description": "Params is a JSON object passed to the algorithm's workload as is.",
+                    "type": "object"
+                }
+            }
+        },
+        "models.AlgoStatuses": {
+            "type": "object",
+            "properties": {
+                "algorithms": {
+                    "description": "Algorithms maps names of the catalog algorithms to whether they are enabled for the client.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "client_id": {
+                    "type": "integer",
+                    "example": 123
+                },
+                "workloads": {
+                    "description": "Workloads maps names of the algorithms to the observed state of their workloads in the cluster.\nIt is read-only and reported only when the statuses are fetched.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.WorkloadState"
+                    }
+                }
+            }
+        },
+        "models.Algorithm": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "type": "string",
+                    "example": "250m"
+                },
+                "enabled": {
+                    "description": "Enabled allows to run the algorithm for the clients, a disabled algorithm is stopped for everyone.\nLeft out on save, it keeps the stored value, a new algorithm is enabled.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "image": {
+                    "description": "Image, CPU and Memory are the defaults for the clients which don't set their own.",
+                    "type": "string",
+                    "example": "vwap:1.4.0"
+                },
+                "memory": {
+                    "type": "string",
+                    "example": "256Mi"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "vwap"
+                }
+            }
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "client.create",
+                        "client.update",
+                        "client.delete",
+                        "params.update",
+                        "algorithms.toggle",
+                        "catalog.save",
+                        "client.restart",
+                        "client.restart_fail",
+                        "workload.phase"
+                    ],
+                    "example": "algorithms.toggle"
+                },
+                "actor": {
+                    "description": "Actor is the authenticated subject which made the change, system for the scheduler",
+                    "type": "string",
+                    "example": "apikey:dashboard"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before and After are the states of the changed object, Before is empty for the created ones and After for the deleted ones",
+                    "type": "object"
+                },
+                "client_id": {
+                    "description": "ClientID is empty for the changes of the algorithm catalog",
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-07-17T14:30:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "host/abcdef-000001"
+                }
+            }
+        },
+        "models.AuditPage": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page, empty on the last page",
+                    "type": "string",
+                    "example": "NDI"
+                }
+            }
+        },
+        "models.Client": {
+            "type": "object",
+            "properties": {
+                "client_name": {
+                    "type": "string",
+                    "example": "Client A"
+                },
+                "cpu": {
+                    "type": "string",
+                    "example": "500m"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-07-01T08:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "image": {
+                    "type": "string",
+                    "example": "client-image:latest"
+                },
+                "memory": {
+                    "type": "string",
+                    "example": "512Mi"
                 },
                 "need_restart": {
                     "type": "boolean",
@@ -260,6 +1575,15 @@ const docTemplate = `{
                     "type": "number",
                     "example": 0.75
                 },
+                "restart_error": {
+                    "type": "string",
+                    "example": "timed out waiting for pods termination"
+                },
+                "revision": {
+                    "description": "Revision is bumped on every update, an update carrying a non-zero revision is applied only if it is still current.",
+                    "type": "integer",
+                    "example": 3
+                },
                 "spawned_at": {
                     "type": "string",
                     "example": "2024-07-17T12:00:00Z"
@@ -274,9 +1598,190 @@ const docTemplate = `{
                 }
             }
         },
+        "models.ClientBatch": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ClientBatchItem"
+                    }
+                }
+            }
+        },
+        "models.ClientBatchItem": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/models.Client"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update"
+                    ],
+                    "example": "create"
+                }
+            }
+        },
+        "models.ClientBatchResponse": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ClientBatchResult"
+                    }
+                }
+            }
+        },
+        "models.ClientBatchResult": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/models.Client"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "client_not_found"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Client not found"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "error",
+                        "rolled_back",
+                        "skipped"
+                    ],
+                    "example": "ok"
+                }
+            }
+        },
+        "models.ClientPage": {
+            "type": "object",
+            "properties": {
+                "clients": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Client"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor fetches the next page, empty on the last page",
+                    "type": "string",
+                    "example": "eyJzIjoiaWQiLCJpZCI6NTB9"
+                },
+                "total": {
+                    "description": "Total is the number of the clients matching the filters on all pages",
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "models.ClientPatch": {
+            "type": "object",
+            "properties": {
+                "client_name": {
+                    "type": "string",
+                    "example": "Client A"
+                },
+                "cpu": {
+                    "type": "string",
+                    "example": "500m"
+                },
+                "image": {
+                    "type": "string",
+                    "example": "client-image:latest"
+                },
+                "memory": {
+                    "type": "string",
+                    "example": "512Mi"
+                },
+                "need_restart": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "priority": {
+                    "type": "number",
+                    "example": 0.75
+                },
+                "revision": {
+                    "description": "Revision the patch is based on, zero applies the patch to any revision.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "models.WorkloadState": {
+            "type": "object",
+            "properties": {
+                "image": {
+                    "type": "string",
+                    "example": "registry.example.com/algo:1.2"
+                },
+                "last_error": {
+                    "description": "LastError is the error of the scheduler's last action on the workload.",
+                    "type": "string"
+                },
+                "last_transition_at": {
+                    "type": "string",
+                    "example": "2024-07-17T14:30:00Z"
+                },
+                "phase": {
+                    "type": "string",
+                    "example": "Running"
+                },
+                "pod": {
+                    "type": "string",
+                    "example": "client-123-vwap"
+                },
+                "reason": {
+                    "description": "Reason explains the phase, e.g. Evicted or CrashLoopBackOff.",
+                    "type": "string",
+                    "example": "CrashLoopBackOff"
+                },
+                "restart_count": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-07-17T14:30:00Z"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
         "response.Response": {
             "type": "object",
             "properties": {
+                "code": {
+                    "description": "Optional machine-readable code of the error, e.g. client_not_found",
+                    "type": "string"
+                },
                 "error": {
                     "description": "Optional error message for error responses",
                     "type": "string"
@@ -291,6 +1796,20 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Static API key",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        },
+        "BearerAuth": {
+            "description": "JWT as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+)
+
+// APIKeyHeader carries the static API key
+const APIKeyHeader = "X-API-Key"
+
+// KeyStorage looks up the API keys by their hashes
+//
+//go:generate mockgen -source=apikey.go -destination=mock/mock.go -package=mock_auth
+type KeyStorage interface {
+	FetchAPIKey(ctx context.Context, hash string) (*models.APIKey, error)
+}
+
+// APIKeys authenticates the requests by the static API keys
+type APIKeys struct {
+	storage KeyStorage
+}
+
+func NewAPIKeys(storage KeyStorage) *APIKeys {
+	return &APIKeys{storage: storage}
+}
+
+func (a *APIKeys) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	apiKey, err := a.storage.FetchAPIKey(r.Context(), HashAPIKey(key))
+	if errors.Is(err, storage.ErrAPIKeyNotFound) {
+		return nil, fmt.Errorf("%w: unknown api key", errInvalidCredentials)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{
+		Subject: "apikey:" + apiKey.Name,
+		Method:  MethodAPIKey,
+	}, nil
+}
+
+// HashAPIKey returns the hex-encoded SHA-256 of the key, the form the keys are stored in
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"sync-algo/internal/lib/apperr"
+	"sync-algo/internal/lib/audit"
+	"sync-algo/internal/lib/logger/sl"
+	"sync-algo/internal/lib/response"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// Methods the principals are authenticated with
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// ErrNoCredentials is returned by the authenticator when the request carries none of its credentials,
+// so the next authenticator is tried
+var ErrNoCredentials = errors.New("no credentials")
+
+var (
+	errUnauthenticated    = apperr.New(apperr.KindUnauthenticated, "unauthenticated", "Authentication required")
+	errInvalidCredentials = apperr.New(apperr.KindUnauthenticated, "invalid_credentials", "Invalid credentials")
+)
+
+// Principal is the authenticated caller of the API
+type Principal struct {
+	// Subject identifies the caller, jwt:<sub> of the token or apikey:<name> of the API key,
+	// the prefix keeps the subjects of different methods apart
+	Subject string
+	Method  string
+	// Role is assigned to the subject, empty until the Roles middleware looks it up
+	Role string
+}
+
+// Authenticator authenticates the request by the credentials of a single kind
+type Authenticator interface {
+	// Authenticate returns ErrNoCredentials if the request carries none of the authenticator's credentials
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns the context carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal of the authenticated request, nil if there is none
+func FromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// Middleware rejects the requests none of the authenticators accepts with 401,
+// the principal of the accepted ones is put into the request's context along with the audit actor
+func Middleware(log *slog.Logger, authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const op = "auth.Middleware"
+
+			log := log.With(
+				slog.String("op", op),
+				slog.String("req_id", middleware.GetReqID(r.Context())),
+			)
+
+			for _, authenticator := range authenticators {
+				principal, err := authenticator.Authenticate(r)
+				if errors.Is(err, ErrNoCredentials) {
+					continue
+				}
+				if err != nil {
+					log.Warn("authentication failed", sl.Error(err))
+					unauthorized(w, r, err)
+					return
+				}
+
+				// The principal is also the actor of the changes made by the request
+				ctx := WithPrincipal(r.Context(), principal)
+				ctx = audit.WithActor(ctx, audit.Actor{
+					Subject:   principal.Subject,
+					RequestID: middleware.GetReqID(r.Context()),
+				})
+
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			log.Warn("request without credentials")
+			unauthorized(w, r, errUnauthenticated)
+		})
+	}
+}
+
+// unauthorized reports the failed authentication, the failures of the storage are reported as they are
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	if apperr.As(err).Kind == apperr.KindUnauthenticated {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="sync-algo"`)
+	}
+
+	response.Error(w, r, err)
+}
@@ -0,0 +1,335 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mock_auth "sync-algo/internal/auth/mock"
+	"sync-algo/internal/config"
+	"sync-algo/internal/lib/logger/handlers/slogdiscard"
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, 7, 17, 12, 0, 0, 0, time.UTC)
+
+func TestMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	keys := mock_auth.NewMockKeyStorage(ctrl)
+	jwt, err := NewJWT(&config.Auth{JWTSecret: "secret"})
+	require.NoError(t, err)
+	jwt.now = func() time.Time { return now }
+
+	handler := Middleware(slogdiscard.NewDiscardLogger(), NewAPIKeys(keys), jwt)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := FromContext(r.Context())
+			fmt.Fprintf(w, `{"subject":%q,"method":%q}`, principal.Subject, principal.Method)
+		}),
+	)
+
+	tt := []struct {
+		name                 string
+		headers              map[string]string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "API key",
+			headers:              map[string]string{APIKeyHeader: "key"},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"subject":"apikey:dashboard","method":"api_key"}`,
+			mockBehavior: func() {
+				keys.EXPECT().FetchAPIKey(gomock.Any(), HashAPIKey("key")).Return(&models.APIKey{ID: 1, Name: "dashboard"}, nil)
+			},
+		},
+		{
+			name:                 "Unknown API key",
+			headers:              map[string]string{APIKeyHeader: "unknown"},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"status":"Error","error":"Invalid credentials","code":"invalid_credentials"}`,
+			mockBehavior: func() {
+				keys.EXPECT().FetchAPIKey(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrAPIKeyNotFound))
+			},
+		},
+		{
+			name:                 "Storage unavailable",
+			headers:              map[string]string{APIKeyHeader: "key"},
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"Error","error":"Storage unavailable","code":"storage_unavailable"}`,
+			mockBehavior: func() {
+				keys.EXPECT().FetchAPIKey(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrUnavailable))
+			},
+		},
+		{
+			name:                 "Bearer token",
+			headers:              map[string]string{"Authorization": "Bearer " + hmacToken(t, "HS256", "secret", map[string]any{"sub": "alice", "exp": now.Add(time.Hour).Unix()})},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"subject":"jwt:alice","method":"jwt"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Expired token",
+			headers:              map[string]string{"Authorization": "Bearer " + hmacToken(t, "HS256", "secret", map[string]any{"sub": "alice", "exp": now.Add(-time.Hour).Unix()})},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"status":"Error","error":"Invalid credentials","code":"invalid_credentials"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "No credentials",
+			headers:              map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"status":"Error","error":"Authentication required","code":"unauthenticated"}`,
+			mockBehavior:         func() {},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("GET", "/clients", nil)
+			for name, value := range tc.headers {
+				req.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+			if tc.expectedStatusCode == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	roles := mock_auth.NewMockRoleStorage(ctrl)
+
+	handler := Roles(slogdiscard.NewDiscardLogger(), roles)(
+		Require(PermissionToggleAlgorithms)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"role":%q}`, FromContext(r.Context()).Role)
+		})),
+	)
+
+	tt := []struct {
+		name                 string
+		principal            *Principal
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "Permission granted",
+			principal:            &Principal{Subject: "jwt:alice", Method: MethodJWT},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"role":"operator"}`,
+			mockBehavior: func() {
+				roles.EXPECT().FetchRole(gomock.Any(), "jwt:alice").Return(RoleOperator, nil)
+			},
+		},
+		{
+			name:                 "Permission denied",
+			principal:            &Principal{Subject: "apikey:dashboard", Method: MethodAPIKey},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"status":"Error","error":"Role viewer is not allowed to toggle algorithms","code":"forbidden"}`,
+			mockBehavior: func() {
+				roles.EXPECT().FetchRole(gomock.Any(), "apikey:dashboard").Return(RoleViewer, nil)
+			},
+		},
+		{
+			name:                 "No role",
+			principal:            &Principal{Subject: "jwt:bob", Method: MethodJWT},
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"status":"Error","error":"No role assigned","code":"no_role"}`,
+			mockBehavior: func() {
+				roles.EXPECT().FetchRole(gomock.Any(), "jwt:bob").Return("", fmt.Errorf("storage: %w", storage.ErrRoleNotAssigned))
+			},
+		},
+		{
+			name:                 "Not authenticated",
+			expectedStatusCode:   http.StatusUnauthorized,
+			expectedResponseBody: `{"status":"Error","error":"Authentication required","code":"unauthenticated"}`,
+			mockBehavior:         func() {},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("PATCH", "/clients/1/algorithms", nil)
+			if tc.principal != nil {
+				req = req.WithContext(WithPrincipal(req.Context(), tc.principal))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	assert.True(t, Allowed(RoleViewer, PermissionRead))
+	assert.False(t, Allowed(RoleViewer, PermissionToggleAlgorithms))
+	assert.True(t, Allowed(RoleOperator, PermissionToggleAlgorithms))
+	assert.False(t, Allowed(RoleOperator, PermissionDeleteClients))
+	assert.True(t, Allowed(RoleAdmin, PermissionDeleteClients))
+	assert.False(t, Allowed("", PermissionRead))
+
+	// Every permission of the matrix is described in the reasons of the denials
+	for _, permissions := range grants {
+		for permission := range permissions {
+			assert.NotEmpty(t, actions[permission], permission)
+		}
+	}
+}
+
+func TestJWT_verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	jwks := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa","use":"sig","n":%q,"e":%q},
+		{"kty":"EC","kid":"ec","crv":"P-256","x":%q,"y":%q},
+		{"kty":"RSA","kid":"enc","use":"enc","n":%q,"e":%q}
+	]}`,
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+		b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))),
+		b64(rsaKey.N.Bytes()), b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+	)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, []byte(jwks), 0o600))
+
+	jwt, err := NewJWT(&config.Auth{JWKSFile: path, JWTIssuer: "issuer", JWTAudience: "sync-algo"})
+	require.NoError(t, err)
+	jwt.now = func() time.Time { return now }
+
+	valid := map[string]any{"sub": "alice", "iss": "issuer", "aud": []string{"sync-algo", "other"}, "exp": now.Add(time.Hour).Unix()}
+	with := func(name string, value any) map[string]any {
+		c := map[string]any{}
+		for k, v := range valid {
+			c[k] = v
+		}
+		c[name] = value
+		return c
+	}
+
+	tt := []struct {
+		name          string
+		token         string
+		expectedError bool
+	}{
+		{name: "RS256", token: rsaToken(t, rsaKey, "rsa", valid)},
+		{name: "ES256", token: ecToken(t, ecKey, "ec", valid)},
+		{name: "Single audience", token: rsaToken(t, rsaKey, "rsa", with("aud", "sync-algo"))},
+		{name: "Expiration within clock skew", token: rsaToken(t, rsaKey, "rsa", with("exp", now.Add(-30*time.Second).Unix()))},
+		{name: "Expired", token: rsaToken(t, rsaKey, "rsa", with("exp", now.Add(-time.Hour).Unix())), expectedError: true},
+		{name: "Without expiration", token: rsaToken(t, rsaKey, "rsa", with("exp", nil)), expectedError: true},
+		{name: "Not valid yet", token: rsaToken(t, rsaKey, "rsa", with("nbf", now.Add(time.Hour).Unix())), expectedError: true},
+		{name: "Unexpected issuer", token: rsaToken(t, rsaKey, "rsa", with("iss", "other")), expectedError: true},
+		{name: "Unexpected audience", token: rsaToken(t, rsaKey, "rsa", with("aud", "other")), expectedError: true},
+		{name: "Without subject", token: rsaToken(t, rsaKey, "rsa", with("sub", "")), expectedError: true},
+		{name: "Unknown key", token: rsaToken(t, rsaKey, "other", valid), expectedError: true},
+		{name: "Encryption key", token: rsaToken(t, rsaKey, "enc", valid), expectedError: true},
+		{name: "Tampered claims", token: tamper(rsaToken(t, rsaKey, "rsa", valid), with("sub", "mallory")), expectedError: true},
+		{name: "HMAC with public key", token: hmacToken(t, "HS256", string(rsaKey.N.Bytes()), valid), expectedError: true},
+		{name: "Algorithm none", token: sign(t, map[string]any{"alg": "none"}, valid, func([]byte) []byte { return nil }), expectedError: true},
+		{name: "Malformed", token: "not-a-token", expectedError: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := jwt.verify(tc.token)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "alice", c.Subject)
+		})
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// sign builds the token with the signature returned by signer for the signing input
+func sign(t *testing.T, header, claims map[string]any, signer func(input []byte) []byte) string {
+	t.Helper()
+
+	rawHeader, err := json.Marshal(header)
+	require.NoError(t, err)
+	rawClaims, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := b64(rawHeader) + "." + b64(rawClaims)
+	return input + "." + b64(signer([]byte(input)))
+}
+
+func hmacToken(t *testing.T, alg, secret string, claims map[string]any) string {
+	return sign(t, map[string]any{"alg": alg, "typ": "JWT"}, claims, func(input []byte) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(input)
+		return mac.Sum(nil)
+	})
+}
+
+func rsaToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	return sign(t, map[string]any{"alg": "RS256", "kid": kid}, claims, func(input []byte) []byte {
+		sum := sha256.Sum256(input)
+		signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+		require.NoError(t, err)
+		return signature
+	})
+}
+
+func ecToken(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]any) string {
+	return sign(t, map[string]any{"alg": "ES256", "kid": kid}, claims, func(input []byte) []byte {
+		sum := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+		require.NoError(t, err)
+		return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	})
+}
+
+// tamper replaces the token's claims keeping its signature
+func tamper(token string, claims map[string]any) string {
+	raw, _ := json.Marshal(claims)
+	parts := strings.Split(token, ".")
+	return parts[0] + "." + b64(raw) + "." + parts[2]
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"sync-algo/internal/config"
+
+	"github.com/MicahParks/jwkset"
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// clockSkew tolerates the difference between the clocks of the token's issuer and the service
+const clockSkew = time.Minute
+
+var (
+	// hmacMethods are accepted with the secret, asymmetricMethods with the keys of the JWKS,
+	// so e.g. a public key can't be used as an HMAC secret
+	hmacMethods       = []string{"HS256", "HS384", "HS512"}
+	asymmetricMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+)
+
+// JWT authenticates the requests by the bearer tokens signed either with the HMAC secret
+// or with a key of the JWKS
+type JWT struct {
+	parser  *jwt.Parser
+	keyfunc jwt.Keyfunc
+	now     func() time.Time
+}
+
+// NewJWT creates the authenticator validating the tokens with the configured HMAC secret or JWKS file
+func NewJWT(cfg *config.Auth) (*JWT, error) {
+	const op = "auth.NewJWT"
+
+	j := &JWT{now: time.Now}
+
+	options := []jwt.ParserOption{
+		jwt.WithLeeway(clockSkew),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(func() time.Time { return j.now() }),
+	}
+	if cfg.JWTIssuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		options = append(options, jwt.WithAudience(cfg.JWTAudience))
+	}
+
+	switch {
+	case cfg.JWTSecret != "":
+		secret := []byte(cfg.JWTSecret)
+		j.keyfunc = func(*jwt.Token) (any, error) { return secret, nil }
+		options = append(options, jwt.WithValidMethods(hmacMethods))
+	case cfg.JWKSFile != "":
+		raw, err := os.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		keys, err := jwksKeyfunc(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		j.keyfunc = keys.Keyfunc
+		options = append(options, jwt.WithValidMethods(asymmetricMethods))
+	default:
+		return nil, fmt.Errorf("%s: neither secret nor JWKS file configured", op)
+	}
+
+	j.parser = jwt.NewParser(options...)
+
+	return j, nil
+}
+
+// jwksKeyfunc looks the token's key up in the JWKS, the keys of other uses than signing are refused
+func jwksKeyfunc(raw []byte) (keyfunc.Keyfunc, error) {
+	var set jwkset.JWKSMarshal
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("malformed JWKS: %w", err)
+	}
+
+	storage, err := set.ToStorage()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	return keyfunc.New(keyfunc.Options{
+		Storage:      storage,
+		UseWhitelist: []jwkset.USE{jwkset.UseSig, ""},
+	})
+}
+
+func (j *JWT) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+
+	c, err := j.verify(strings.TrimSpace(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidCredentials, err)
+	}
+
+	return &Principal{
+		Subject: "jwt:" + c.Subject,
+		Method:  MethodJWT,
+	}, nil
+}
+
+// verify checks the token's signature and registered claims, returning the claims of the valid token
+func (j *JWT) verify(token string) (*jwt.RegisteredClaims, error) {
+	var c jwt.RegisteredClaims
+	if _, err := j.parser.ParseWithClaims(token, &c, j.keyfunc); err != nil {
+		return nil, err
+	}
+
+	if c.Subject == "" {
+		return nil, errors.New("token without subject")
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: apikey.go
+
+// Package mock_auth is a generated GoMock package.
+package mock_auth
+
+import (
+	context "context"
+	reflect "reflect"
+	models "sync-algo/internal/models"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockKeyStorage is a mock of KeyStorage interface.
+type MockKeyStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyStorageMockRecorder
+}
+
+// MockKeyStorageMockRecorder is the mock recorder for MockKeyStorage.
+type MockKeyStorageMockRecorder struct {
+	mock *MockKeyStorage
+}
+
+// NewMockKeyStorage creates a new mock instance.
+func NewMockKeyStorage(ctrl *gomock.Controller) *MockKeyStorage {
+	mock := &MockKeyStorage{ctrl: ctrl}
+	mock.recorder = &MockKeyStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyStorage) EXPECT() *MockKeyStorageMockRecorder {
+	return m.recorder
+}
+
+// FetchAPIKey mocks base method.
+func (m *MockKeyStorage) FetchAPIKey(ctx context.Context, hash string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAPIKey", ctx, hash)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAPIKey indicates an expected call of FetchAPIKey.
+func (mr *MockKeyStorageMockRecorder) FetchAPIKey(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAPIKey", reflect.TypeOf((*MockKeyStorage)(nil).FetchAPIKey), ctx, hash)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: roles.go
+
+// Package mock_auth is a generated GoMock package.
+package mock_auth
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRoleStorage is a mock of RoleStorage interface.
+type MockRoleStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleStorageMockRecorder
+}
+
+// MockRoleStorageMockRecorder is the mock recorder for MockRoleStorage.
+type MockRoleStorageMockRecorder struct {
+	mock *MockRoleStorage
+}
+
+// NewMockRoleStorage creates a new mock instance.
+func NewMockRoleStorage(ctrl *gomock.Controller) *MockRoleStorage {
+	mock := &MockRoleStorage{ctrl: ctrl}
+	mock.recorder = &MockRoleStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoleStorage) EXPECT() *MockRoleStorageMockRecorder {
+	return m.recorder
+}
+
+// FetchRole mocks base method.
+func (m *MockRoleStorage) FetchRole(ctx context.Context, subject string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchRole", ctx, subject)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchRole indicates an expected call of FetchRole.
+func (mr *MockRoleStorageMockRecorder) FetchRole(ctx, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchRole", reflect.TypeOf((*MockRoleStorage)(nil).FetchRole), ctx, subject)
+}
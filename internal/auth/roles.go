@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"sync-algo/internal/lib/apperr"
+	"sync-algo/internal/lib/logger/sl"
+	"sync-algo/internal/lib/response"
+
+	"github.com/go-chi/chi/middleware"
+)
+
+// Roles of the callers, from the least privileged to the most privileged one
+const (
+	// RoleViewer only reads, e.g. for the dashboards
+	RoleViewer = "viewer"
+	// RoleOperator toggles the algorithms and tunes their parameters
+	RoleOperator = "operator"
+	// RoleAdmin can do everything
+	RoleAdmin = "admin"
+)
+
+// Permission is an operation of the API a role may be allowed to perform
+type Permission string
+
+const (
+	PermissionRead             Permission = "read"
+	PermissionToggleAlgorithms Permission = "algorithms:toggle"
+	PermissionWriteParams      Permission = "params:write"
+	PermissionWriteClients     Permission = "clients:write"
+	PermissionDeleteClients    Permission = "clients:delete"
+	PermissionWriteCatalog     Permission = "catalog:write"
+	PermissionReadAudit        Permission = "audit:read"
+)
+
+// actions describe the permissions in the reasons of the denied requests
+var actions = map[Permission]string{
+	PermissionRead:             "read clients and algorithms",
+	PermissionToggleAlgorithms: "toggle algorithms",
+	PermissionWriteParams:      "change algorithm parameters",
+	PermissionWriteClients:     "create and update clients",
+	PermissionDeleteClients:    "delete clients",
+	PermissionWriteCatalog:     "change the algorithm catalog",
+	PermissionReadAudit:        "read the audit log",
+}
+
+// grants is the permission matrix of the roles
+var grants = map[string]map[Permission]bool{
+	RoleViewer: {
+		PermissionRead: true,
+	},
+	RoleOperator: {
+		PermissionRead:             true,
+		PermissionToggleAlgorithms: true,
+		PermissionWriteParams:      true,
+	},
+	RoleAdmin: {
+		PermissionRead:             true,
+		PermissionToggleAlgorithms: true,
+		PermissionWriteParams:      true,
+		PermissionWriteClients:     true,
+		PermissionDeleteClients:    true,
+		PermissionWriteCatalog:     true,
+		PermissionReadAudit:        true,
+	},
+}
+
+// RoleStorage looks up the roles assigned to the callers
+//
+//go:generate mockgen -source=roles.go -destination=mock/roles.go -package=mock_auth
+type RoleStorage interface {
+	FetchRole(ctx context.Context, subject string) (string, error)
+}
+
+// Allowed reports whether the role is granted the permission
+func Allowed(role string, permission Permission) bool {
+	return grants[role][permission]
+}
+
+// Roles puts the role assigned to the authenticated caller into the principal,
+// the callers without a role are rejected with 403
+func Roles(log *slog.Logger, storage RoleStorage) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			const op = "auth.Roles"
+
+			log := log.With(
+				slog.String("op", op),
+				slog.String("req_id", middleware.GetReqID(r.Context())),
+			)
+
+			principal := FromContext(r.Context())
+			if principal == nil {
+				log.Error("roles looked up before authentication")
+				response.Error(w, r, errUnauthenticated)
+				return
+			}
+
+			role, err := storage.FetchRole(r.Context(), principal.Subject)
+			if err != nil {
+				log.Warn("failed to fetch role", slog.String("subject", principal.Subject), sl.Error(err))
+				response.Error(w, r, err)
+				return
+			}
+
+			withRole := *principal
+			withRole.Role = role
+
+			next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), &withRole)))
+		})
+	}
+}
+
+// Require rejects the requests of the callers whose role isn't granted the permission with 403
+func Require(permission Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := FromContext(r.Context())
+			if principal == nil {
+				response.Error(w, r, errUnauthenticated)
+				return
+			}
+
+			if !Allowed(principal.Role, permission) {
+				response.Error(w, r, apperr.New(apperr.KindForbidden, "forbidden",
+					fmt.Sprintf("Role %s is not allowed to %s", principal.Role, actions[permission])))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
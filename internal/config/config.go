@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -14,6 +15,8 @@ type Config struct {
 	*Storage
 	*Server
 	*Kubernates
+	*Scheduler
+	*Auth
 }
 
 type Storage struct {
@@ -30,9 +33,51 @@ type Server struct {
 	Timeout time.Duration
 }
 
+// Deploy modes
+const (
+	// ModePod runs every workload as a bare Pod
+	ModePod = "pod"
+	// ModeDeployment runs every workload as a Deployment
+	ModeDeployment = "deployment"
+)
+
 type Kubernates struct {
-	KubeConfig    string
+	// KubeConfig is a path to kubeconfig, in-cluster service account credentials are used if empty
+	KubeConfig string
+	// KubeContext selects the kubeconfig context, the current one is used if empty.
+	// Set without KubeConfig it selects the context of the default kubeconfig, e.g. ~/.kube/config
+	KubeContext   string
 	ConteinerName string
+	Mode          string
+	Namespace     string
+	// NamespacePerClient puts workloads of every client into a separate namespace prefixed with Namespace
+	NamespacePerClient bool
+	// PriorityClasses are ordered from the lowest priority to the highest one
+	PriorityClasses []string
+	// Timeout bounds every single request to the Kubernetes API
+	Timeout time.Duration
+}
+
+type Scheduler struct {
+	// SyncInterval is the period of the full sync, performed besides the syncs on changes
+	SyncInterval time.Duration
+}
+
+// Auth configures the validation of the JWT bearer tokens, the API keys are always accepted.
+// The tokens are accepted only if either the secret or the JWKS file is set
+type Auth struct {
+	// JWTSecret validates the tokens signed with HMAC
+	JWTSecret string
+	// JWKSFile is a path to the JWKS validating the tokens signed with RSA or ECDSA
+	JWKSFile string
+	// JWTIssuer and JWTAudience are required in the tokens if set
+	JWTIssuer   string
+	JWTAudience string
+}
+
+// JWTEnabled reports whether the bearer tokens are accepted
+func (a *Auth) JWTEnabled() bool {
+	return a.JWTSecret != "" || a.JWKSFile != ""
 }
 
 func MustLoad() *Config {
@@ -46,6 +91,24 @@ func MustLoad() *Config {
 		log.Panic("Error loading SERVER_TIMEOUT variable")
 	}
 
+	namespacePerClient := false
+	if value := os.Getenv("NAMESPACE_PER_CLIENT"); value != "" {
+		namespacePerClient, err = strconv.ParseBool(value)
+		if err != nil {
+			log.Panic("Error loading NAMESPACE_PER_CLIENT variable")
+		}
+	}
+
+	jwtSecret, jwksFile := os.Getenv("JWT_SECRET"), os.Getenv("JWT_JWKS_FILE")
+	if jwtSecret != "" && jwksFile != "" {
+		log.Panic("Only one of JWT_SECRET and JWT_JWKS_FILE can be set")
+	}
+
+	namespace := os.Getenv("KUBE_NAMESPACE")
+	if namespace == "" {
+		namespace = "default"
+	}
+
 	return &Config{
 		os.Getenv("ENV"),
 		&Storage{
@@ -61,8 +124,63 @@ func MustLoad() *Config {
 			Timeout: time.Duration(timeout),
 		},
 		&Kubernates{
-			KubeConfig:    os.Getenv("KUBECONFIG"),
-			ConteinerName: os.Getenv("CONTAINER_IMAGE"),
+			KubeConfig:         os.Getenv("KUBECONFIG"),
+			KubeContext:        os.Getenv("KUBE_CONTEXT"),
+			ConteinerName:      os.Getenv("CONTAINER_IMAGE"),
+			Mode:               deployMode(os.Getenv("DEPLOY_MODE")),
+			Namespace:          namespace,
+			NamespacePerClient: namespacePerClient,
+			PriorityClasses:    splitList(os.Getenv("PRIORITY_CLASSES")),
+			Timeout:            duration("KUBE_TIMEOUT", 30*time.Second),
 		},
+		&Scheduler{
+			SyncInterval: duration("SYNC_INTERVAL", 5*time.Minute),
+		},
+		&Auth{
+			JWTSecret:   jwtSecret,
+			JWKSFile:    jwksFile,
+			JWTIssuer:   os.Getenv("JWT_ISSUER"),
+			JWTAudience: os.Getenv("JWT_AUDIENCE"),
+		},
+	}
+}
+
+// splitList splits comma-separated variable value, skipping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// duration parses positive duration variable like "30s", defaulting to def
+func duration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Panicf("Error loading %s variable", name)
+	}
+
+	return d
+}
+
+// deployMode validates the deploy mode, defaulting to pods
+func deployMode(mode string) string {
+	switch mode {
+	case "":
+		return ModePod
+	case ModePod, ModeDeployment:
+		return mode
+	default:
+		log.Panicf("Unknown DEPLOY_MODE %q", mode)
+		return ""
 	}
 }
@@ -2,8 +2,15 @@ package algorithm
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
+	"strconv"
+	"sync-algo/internal/auth"
+	"sync-algo/internal/lib/apperr"
+	"sync-algo/internal/lib/bulk"
 	"sync-algo/internal/lib/logger/sl"
 	"sync-algo/internal/lib/response"
 	"sync-algo/internal/models"
@@ -11,17 +18,40 @@ import (
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var (
 	emptyValue = 0
 )
 
+// maxBatchItems limits the number of items in a single batch request
+const maxBatchItems = 1000
+
+// maxNameLength keeps the workload names built from the algorithm names valid for Kubernetes
+const maxNameLength = 40
+
+// namePattern restricts the algorithm names to lowercase labels usable in the workload names
+var namePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// Errors of the invalid requests, the service's errors are reported as they are
+var (
+	errInvalidBody      = apperr.BadRequest("invalid_body", "Invalid request body")
+	errInvalidClientID  = apperr.BadRequest("invalid_client_id", "Invalid client id")
+	errInvalidName      = apperr.Validation("invalid_algorithm_name", "Invalid algorithm name")
+	errNoAlgorithms     = apperr.Validation("no_algorithms", "Invalid data")
+	errInvalidResources = apperr.Validation("invalid_resources", "Invalid resources")
+	errInvalidBatchSize = apperr.BadRequest("invalid_batch_size", fmt.Sprintf("Batch must contain from 1 to %d items", maxBatchItems))
+)
+
 // Service defines the interface for managing algorithm statuses.
 //
 //go:generate mockgen -source=algorithm.go -destination=mock/mock.go -package=algorithm
 type Service interface {
 	UpdateStatuses(ctx context.Context, algoStatuses *models.AlgoStatuses) (*models.AlgoStatuses, error)
+	ApplyBatch(ctx context.Context, batch *models.AlgoBatch) ([]*models.AlgoStatuses, []error, error)
+	ListAlgorithms(ctx context.Context) ([]models.Algorithm, error)
+	SaveAlgorithm(ctx context.Context, algorithm *models.Algorithm) (*models.Algorithm, error)
 }
 
 type Handler struct {
@@ -39,20 +69,43 @@ func New(service Service, log *slog.Logger) *Handler {
 // Register registers the API routes
 func (h *Handler) Register() func(r chi.Router) {
 	return func(r chi.Router) {
-		r.Patch("/", h.updateAlgorithmStatus)
+		r.With(auth.Require(auth.PermissionRead)).Get("/", h.listAlgorithms)
+		r.With(auth.Require(auth.PermissionWriteCatalog)).Put("/{name}", h.saveAlgorithm)
+	}
+}
+
+// RegisterClient registers the routes of the client's algorithms, which live under the /clients routes.
+func (h *Handler) RegisterClient() func(r chi.Router) {
+	return func(r chi.Router) {
+		r.With(auth.Require(auth.PermissionToggleAlgorithms)).Patch("/{id}/algorithms", h.updateAlgorithmStatus)
+	}
+}
+
+// RegisterBatch registers the bulk routes, which live next to the /algorithms routes rather than under them.
+func (h *Handler) RegisterBatch() func(r chi.Router) {
+	return func(r chi.Router) {
+		r.With(auth.Require(auth.PermissionToggleAlgorithms)).Patch("/algorithms:batch", h.batchAlgorithmStatuses)
 	}
 }
 
 // @Summary Update algorithm statuses
-// @Description Update the status of algorithms based on the provided data.
+// @Description Enable and disable the client's algorithms, the algorithms missing in the body are left as they are.
 // @Tags algorithms
 // @Accept json
 // @Produce json
+// @Param id path int true "Client ID"
 // @Param body body models.AlgoStatuses true "Algorithm statuses to update"
 // @Success 200 {object} models.AlgoStatuses "Updated algorithm statuses"
-// @Failure 400 {object} response.Response "Invalid credentials or data"
+// @Failure 400 {object} response.Response "Invalid client id or body"
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 404 {object} response.Response "Client not found"
+// @Failure 422 {object} response.Response "No algorithms or unknown algorithm"
 // @Failure 500 {object} response.Response "Internal error"
-// @Router /algorithm/ [patch]
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/{id}/algorithms [patch]
 func (h *Handler) updateAlgorithmStatus(w http.ResponseWriter, r *http.Request) {
 	const op = "controller.algorithm.updateAlgorithmStatus"
 
@@ -63,29 +116,36 @@ func (h *Handler) updateAlgorithmStatus(w http.ResponseWriter, r *http.Request)
 
 	log.Debug("updating algorithms status...")
 
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Error("failed to extract client id from request params", sl.Error(err))
+		response.Error(w, r, errInvalidClientID)
+		return
+	}
+
 	// Decode the request body
 	var algoStatuses models.AlgoStatuses
-	err := render.Decode(r, &algoStatuses)
+	err = render.Decode(r, &algoStatuses)
 	if err != nil {
 		log.Error("failed to extract request body", sl.Error(err))
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, response.Err("Invalid credentials"))
+		response.Error(w, r, errInvalidBody)
 		return
 	}
 
 	// Validate the received data
-	if algoStatuses.ClientID == emptyValue || !(algoStatuses.VWAP != nil || algoStatuses.TWAP != nil || algoStatuses.HFT != nil) {
+	if len(algoStatuses.Algorithms) == 0 {
 		log.Error("invalid data provided")
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, response.Err("Invalid data"))
+		response.Error(w, r, errNoAlgorithms)
 		return
 	}
 
+	// The client is addressed by the path, the body's one is ignored
+	algoStatuses.ClientID = id
+
 	// Call the service to update the algorithm statuses
 	updatedStatuses, err := h.service.UpdateStatuses(r.Context(), &algoStatuses)
 	if err != nil {
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, response.Err("Internal error"))
+		response.Error(w, r, err)
 		return
 	}
 
@@ -94,3 +154,214 @@ func (h *Handler) updateAlgorithmStatus(w http.ResponseWriter, r *http.Request)
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, updatedStatuses)
 }
+
+// @Summary Toggle algorithms in bulk
+// @Description Apply algorithm toggles of many clients in a single transaction.
+// @Description An atomic batch is applied all-or-nothing: if any item is invalid, none is tried,
+// @Description the invalid items are reported as errors and the others as skipped.
+// @Description Otherwise the result of every item, including the invalid ones, is reported separately.
+// @Tags algorithms
+// @Accept json
+// @Produce json
+// @Param body body models.AlgoBatch true "Algorithm statuses to update"
+// @Success 200 {object} models.AlgoBatchResponse
+// @Failure 400 {object} response.Response "Invalid body or batch size"
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 422 {object} models.AlgoBatchResponse "Atomic batch with invalid items or rolled back"
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /algorithms:batch [patch]
+func (h *Handler) batchAlgorithmStatuses(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.algorithm.batchAlgorithmStatuses"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("applying algorithm batch...")
+
+	var batch models.AlgoBatch
+	err := render.Decode(r, &batch)
+	if err != nil {
+		log.Error("failed to extract batch from request body", sl.Error(err))
+		response.Error(w, r, errInvalidBody)
+		return
+	}
+
+	if len(batch.Items) == 0 || len(batch.Items) > maxBatchItems {
+		log.Error("invalid batch size", slog.Int("items", len(batch.Items)))
+		response.Error(w, r, errInvalidBatchSize)
+		return
+	}
+
+	applied, results, err := bulk.Apply(batch.Items, batch.Atomic, validateBatchItem,
+		func(valid []models.AlgoStatuses) ([]*models.AlgoStatuses, []error, error) {
+			return h.service.ApplyBatch(r.Context(), &models.AlgoBatch{Atomic: batch.Atomic, Items: valid})
+		},
+	)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	resp := models.AlgoBatchResponse{
+		Applied: applied,
+		Results: make([]models.AlgoBatchResult, len(results)),
+	}
+	for i, result := range results {
+		resp.Results[i] = models.AlgoBatchResult{
+			Index:    i,
+			Status:   result.Status,
+			Statuses: result.Value,
+			Error:    result.Error,
+			Code:     result.Code,
+		}
+	}
+
+	log.Debug("algorithm batch applied", slog.Bool("applied", resp.Applied))
+
+	if !resp.Applied {
+		render.Status(r, http.StatusUnprocessableEntity)
+	} else {
+		render.Status(r, http.StatusOK)
+	}
+	render.JSON(w, r, resp)
+}
+
+// @Summary List algorithms
+// @Description Get the algorithm catalog
+// @Tags algorithms
+// @Produce json
+// @Success 200 {array} models.Algorithm
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /algorithms/ [get]
+func (h *Handler) listAlgorithms(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.algorithm.listAlgorithms"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("listing algorithms...")
+
+	algorithms, err := h.service.ListAlgorithms(r.Context())
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, algorithms)
+}
+
+// @Summary Save an algorithm
+// @Description Add the algorithm to the catalog or replace its default image and resources.
+// @Description Disabling the algorithm stops it for every client.
+// @Tags algorithms
+// @Accept json
+// @Produce json
+// @Param name path string true "Algorithm name"
+// @Param body body models.Algorithm true "Algorithm defaults"
+// @Success 200 {object} models.Algorithm
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /algorithms/{name} [put]
+func (h *Handler) saveAlgorithm(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.algorithm.saveAlgorithm"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("saving algorithm...")
+
+	name := chi.URLParam(r, "name")
+	if len(name) > maxNameLength || !namePattern.MatchString(name) {
+		log.Error("invalid algorithm name", slog.String("name", name))
+		response.Error(w, r, errInvalidName)
+		return
+	}
+
+	var algorithm models.Algorithm
+	err := render.Decode(r, &algorithm)
+	if err != nil {
+		log.Error("failed to extract request body", sl.Error(err))
+		response.Error(w, r, errInvalidBody)
+		return
+	}
+
+	if err := validateResources(&algorithm); err != nil {
+		log.Error("invalid algorithm resources", sl.Error(err))
+		response.Error(w, r, errInvalidResources)
+		return
+	}
+
+	algorithm.Name = name
+
+	saved, err := h.service.SaveAlgorithm(r.Context(), &algorithm)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	log.Debug("algorithm saved successfully")
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, saved)
+}
+
+// validateBatchItem checks the batch item toggles some algorithms of a client
+func validateBatchItem(item *models.AlgoStatuses) *apperr.Error {
+	if item.ClientID == emptyValue || len(item.Algorithms) == 0 {
+		return errNoAlgorithms
+	}
+
+	return nil
+}
+
+// validateResources checks the default resources are valid positive Kubernetes quantities
+func validateResources(algorithm *models.Algorithm) error {
+	if algorithm.CPU != "" {
+		if err := validateQuantity(algorithm.CPU); err != nil {
+			return fmt.Errorf("invalid cpu %q: %w", algorithm.CPU, err)
+		}
+	}
+
+	if algorithm.Memory != "" {
+		if err := validateQuantity(algorithm.Memory); err != nil {
+			return fmt.Errorf("invalid memory %q: %w", algorithm.Memory, err)
+		}
+	}
+
+	return nil
+}
+
+// validateQuantity checks the resource is a valid Kubernetes quantity, which is also positive
+func validateQuantity(value string) error {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return err
+	}
+	if q.Sign() <= 0 {
+		return errors.New("must be positive")
+	}
+
+	return nil
+}
@@ -3,15 +3,18 @@ package algorithm
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"sync-algo/internal/auth"
 	mock_service "sync-algo/internal/controller/algorithm/mock"
 	"sync-algo/internal/lib/logger/handlers/slogdiscard"
 	models "sync-algo/internal/models"
+	"sync-algo/internal/storage"
 
-	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/v5"
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -21,6 +24,7 @@ func TestHandler_updateAlgorithmStatus(t *testing.T) {
 
 	tt := []struct {
 		name                 string
+		url                  string
 		inputBody            string
 		inputAlgoStatuses    models.AlgoStatuses
 		expectedStatusCode   int
@@ -29,35 +33,76 @@ func TestHandler_updateAlgorithmStatus(t *testing.T) {
 	}{
 		{
 			name:                 "Update algorithm statuses successfully",
-			inputBody:            `{"client_id": 1, "vwap": true, "twap": false, "hft": true}`,
-			inputAlgoStatuses:    models.AlgoStatuses{ClientID: 1, VWAP: boolPtr(true), TWAP: boolPtr(false), HFT: boolPtr(true)},
+			url:                  "/clients/1/algorithms",
+			inputBody:            `{"algorithms": {"vwap": true, "twap": false, "hft": true}}`,
+			inputAlgoStatuses:    models.AlgoStatuses{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": true}},
 			expectedStatusCode:   http.StatusOK,
-			expectedResponseBody: `{"client_id": 1, "vwap": true, "twap": false, "hft": true}`,
+			expectedResponseBody: `{"client_id": 1, "algorithms": {"vwap": true, "twap": false, "hft": true}}`,
 			mockBehavior: func(s *mock_service.MockService, algoStatuses *models.AlgoStatuses) {
 				s.EXPECT().UpdateStatuses(gomock.Any(), algoStatuses).Return(algoStatuses, nil)
 			},
 		},
+		{
+			name:                 "Address client by path",
+			url:                  "/clients/2/algorithms",
+			inputBody:            `{"client_id": 1, "algorithms": {"hft": false}}`,
+			inputAlgoStatuses:    models.AlgoStatuses{ClientID: 2, Algorithms: map[string]bool{"hft": false}},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"client_id": 2, "algorithms": {"hft": false}}`,
+			mockBehavior: func(s *mock_service.MockService, algoStatuses *models.AlgoStatuses) {
+				s.EXPECT().UpdateStatuses(gomock.Any(), algoStatuses).Return(algoStatuses, nil)
+			},
+		},
+		{
+			name:                 "Invalid client ID in URL",
+			url:                  "/clients/invalid/algorithms",
+			inputBody:            `{"algorithms": {"vwap": true}}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid client id","code":"invalid_client_id"}`,
+		},
 		{
 			name:                 "Invalid JSON body",
+			url:                  "/clients/1/algorithms",
 			inputBody:            `invalid JSON`,
 			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: `{"status":"Error","error":"Invalid credentials"}`,
-			mockBehavior:         nil,
+			expectedResponseBody: `{"status":"Error","error":"Invalid request body","code":"invalid_body"}`,
 		},
 		{
-			name:                 "Missing ClientID",
-			inputBody:            `{"vwap": true}`,
-			inputAlgoStatuses:    models.AlgoStatuses{VWAP: boolPtr(true)},
-			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: `{"status":"Error","error":"Invalid data"}`,
-			mockBehavior:         nil,
+			name:                 "Missing algorithms",
+			url:                  "/clients/1/algorithms",
+			inputBody:            `{"algorithms": {}}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid data","code":"no_algorithms"}`,
+		},
+		{
+			name:                 "Client not found",
+			url:                  "/clients/9/algorithms",
+			inputBody:            `{"algorithms": {"vwap": true}}`,
+			inputAlgoStatuses:    models.AlgoStatuses{ClientID: 9, Algorithms: map[string]bool{"vwap": true}},
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"status":"Error","error":"Client not found","code":"client_not_found"}`,
+			mockBehavior: func(s *mock_service.MockService, algoStatuses *models.AlgoStatuses) {
+				s.EXPECT().UpdateStatuses(gomock.Any(), algoStatuses).Return(nil, fmt.Errorf("storage: %w", storage.ErrUserNotFound))
+			},
+		},
+		{
+			name:                 "Unknown algorithm",
+			url:                  "/clients/1/algorithms",
+			inputBody:            `{"algorithms": {"pov": true}}`,
+			inputAlgoStatuses:    models.AlgoStatuses{ClientID: 1, Algorithms: map[string]bool{"pov": true}},
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Unknown algorithm","code":"unknown_algorithm"}`,
+			mockBehavior: func(s *mock_service.MockService, algoStatuses *models.AlgoStatuses) {
+				s.EXPECT().UpdateStatuses(gomock.Any(), algoStatuses).Return(nil, fmt.Errorf("storage: %w", storage.ErrUnknownAlgorithm))
+			},
 		},
 		{
 			name:                 "Service error",
-			inputBody:            `{"client_id": 1, "vwap": true, "twap": false, "hft": true}`,
-			inputAlgoStatuses:    models.AlgoStatuses{ClientID: 1, VWAP: boolPtr(true), TWAP: boolPtr(false), HFT: boolPtr(true)},
+			url:                  "/clients/1/algorithms",
+			inputBody:            `{"algorithms": {"vwap": true, "twap": false, "hft": true}}`,
+			inputAlgoStatuses:    models.AlgoStatuses{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": true}},
 			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: `{"status":"Error","error":"Internal error"}`,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
 			mockBehavior: func(s *mock_service.MockService, algoStatuses *models.AlgoStatuses) {
 				s.EXPECT().UpdateStatuses(gomock.Any(), algoStatuses).Return(nil, errors.New("internal service error"))
 			},
@@ -80,10 +125,11 @@ func TestHandler_updateAlgorithmStatus(t *testing.T) {
 
 			// Test server
 			r := chi.NewRouter()
-			r.Patch("/algorithms/statuses", handler.updateAlgorithmStatus)
+			r.Use(withRole(auth.RoleOperator))
+			r.Route("/clients", handler.RegisterClient())
 
 			// Test request
-			req := httptest.NewRequest("PATCH", "/algorithms/statuses", bytes.NewBufferString(tc.inputBody))
+			req := httptest.NewRequest("PATCH", tc.url, bytes.NewBufferString(tc.inputBody))
 			req.Header.Set("Content-Type", "application/json")
 
 			w := httptest.NewRecorder()
@@ -96,6 +142,300 @@ func TestHandler_updateAlgorithmStatus(t *testing.T) {
 	}
 }
 
+func TestHandler_batchAlgorithmStatuses(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockService)
+
+	tt := []struct {
+		name                 string
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         mockBehavior
+	}{
+		{
+			name:                 "Apply batch successfully",
+			inputBody:            `{"atomic": true, "items": [{"client_id": 1, "algorithms": {"hft": false}}, {"client_id": 2, "algorithms": {"hft": false}}]}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"applied":true,"results":[{"index":0,"status":"ok","statuses":{"client_id":1,"algorithms": {"vwap": true, "twap": false, "hft": false}}},{"index":1,"status":"ok","statuses":{"client_id":2,"algorithms": {"vwap": false, "twap": false, "hft": false}}}]}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().ApplyBatch(gomock.Any(), &models.AlgoBatch{
+					Atomic: true,
+					Items: []models.AlgoStatuses{
+						{ClientID: 1, Algorithms: map[string]bool{"hft": false}},
+						{ClientID: 2, Algorithms: map[string]bool{"hft": false}},
+					},
+				}).Return([]*models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": false}},
+					{ClientID: 2, Algorithms: map[string]bool{"vwap": false, "twap": false, "hft": false}},
+				}, []error{nil, nil}, nil)
+			},
+		},
+		{
+			name:                 "Report every item separately",
+			inputBody:            `{"items": [{"client_id": 1, "algorithms": {"hft": false}}, {"client_id": 9, "algorithms": {"hft": false}}]}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"applied":true,"results":[{"index":0,"status":"ok","statuses":{"client_id":1,"algorithms": {"hft": false}}},{"index":1,"status":"error","error":"Client not found","code":"client_not_found"}]}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().ApplyBatch(gomock.Any(), gomock.Any()).Return(
+					[]*models.AlgoStatuses{{ClientID: 1, Algorithms: map[string]bool{"hft": false}}, nil},
+					[]error{nil, storage.ErrUserNotFound},
+					nil,
+				)
+			},
+		},
+		{
+			name:                 "Roll back atomic batch",
+			inputBody:            `{"atomic": true, "items": [{"client_id": 1, "algorithms": {"hft": false}}, {"client_id": 9, "algorithms": {"hft": false}}, {"client_id": 3, "algorithms": {"hft": false}}]}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"applied":false,"results":[{"index":0,"status":"rolled_back"},{"index":1,"status":"error","error":"Client not found","code":"client_not_found"},{"index":2,"status":"skipped"}]}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().ApplyBatch(gomock.Any(), gomock.Any()).Return(
+					[]*models.AlgoStatuses{{ClientID: 1, Algorithms: map[string]bool{"hft": false}}, nil, nil},
+					[]error{nil, storage.ErrUserNotFound, nil},
+					fmt.Errorf("storage: %w", storage.ErrBatchAborted),
+				)
+			},
+		},
+		{
+			name:                 "Empty batch",
+			inputBody:            `{"items": []}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Batch must contain from 1 to 1000 items","code":"invalid_batch_size"}`,
+		},
+		{
+			name:                 "Item without algorithms",
+			inputBody:            `{"atomic": true, "items": [{"client_id": 1, "algorithms": {"hft": true}}, {"client_id": 2}]}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"applied":false,"results":[{"index":0,"status":"skipped"},{"index":1,"status":"error","error":"Invalid data","code":"no_algorithms"}]}`,
+		},
+		{
+			name:                 "Report invalid items of non-atomic batch separately",
+			inputBody:            `{"items": [{"client_id": 2}, {"client_id": 1, "algorithms": {"hft": true}}]}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"applied":true,"results":[{"index":0,"status":"error","error":"Invalid data","code":"no_algorithms"},{"index":1,"status":"ok","statuses":{"client_id":1,"algorithms": {"hft": true}}}]}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().ApplyBatch(gomock.Any(), &models.AlgoBatch{
+					Items: []models.AlgoStatuses{{ClientID: 1, Algorithms: map[string]bool{"hft": true}}},
+				}).Return([]*models.AlgoStatuses{{ClientID: 1, Algorithms: map[string]bool{"hft": true}}}, []error{nil}, nil)
+			},
+		},
+		{
+			name:                 "Service error",
+			inputBody:            `{"items": [{"client_id": 1, "algorithms": {"hft": true}}]}`,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().ApplyBatch(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_service.NewMockService(ctrl)
+			if tc.mockBehavior != nil {
+				tc.mockBehavior(service)
+			}
+
+			log := slogdiscard.NewDiscardLogger()
+			handler := New(service, log)
+
+			// Test server
+			r := chi.NewRouter()
+			r.Patch("/algorithms:batch", handler.batchAlgorithmStatuses)
+
+			// Test request
+			req := httptest.NewRequest("PATCH", "/algorithms:batch", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// Assert response
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_listAlgorithms(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockService)
+
+	tt := []struct {
+		name                 string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         mockBehavior
+	}{
+		{
+			name:                 "List algorithms successfully",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `[{"name":"hft","image":"hft:v3","enabled":false},{"name":"vwap","cpu":"250m","memory":"256Mi","enabled":true}]`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().ListAlgorithms(gomock.Any()).Return([]models.Algorithm{
+					{Name: "hft", Image: "hft:v3", Enabled: boolPtr(false)},
+					{Name: "vwap", CPU: "250m", Memory: "256Mi", Enabled: boolPtr(true)},
+				}, nil)
+			},
+		},
+		{
+			name:                 "Service error",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().ListAlgorithms(gomock.Any()).Return(nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_service.NewMockService(ctrl)
+			tc.mockBehavior(service)
+
+			log := slogdiscard.NewDiscardLogger()
+			handler := New(service, log)
+
+			// Test server
+			r := chi.NewRouter()
+			r.Get("/algorithms", handler.listAlgorithms)
+
+			// Test request
+			req := httptest.NewRequest("GET", "/algorithms", nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// Assert response
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_saveAlgorithm(t *testing.T) {
+	type mockBehavior func(s *mock_service.MockService)
+
+	tt := []struct {
+		name                 string
+		algorithmName        string
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         mockBehavior
+	}{
+		{
+			name:                 "Save algorithm successfully",
+			algorithmName:        "pov",
+			inputBody:            `{"name": "ignored", "image": "pov:v1", "cpu": "250m", "enabled": true}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"name":"pov","image":"pov:v1","cpu":"250m","enabled":true}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				algorithm := &models.Algorithm{Name: "pov", Image: "pov:v1", CPU: "250m", Enabled: boolPtr(true)}
+				s.EXPECT().SaveAlgorithm(gomock.Any(), algorithm).Return(algorithm, nil)
+			},
+		},
+		{
+			name:                 "Keep enabled flag when left out",
+			algorithmName:        "pov",
+			inputBody:            `{"image": "pov:v2"}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"name":"pov","image":"pov:v2","enabled":false}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().SaveAlgorithm(gomock.Any(), &models.Algorithm{Name: "pov", Image: "pov:v2"}).
+					Return(&models.Algorithm{Name: "pov", Image: "pov:v2", Enabled: boolPtr(false)}, nil)
+			},
+		},
+		{
+			name:                 "Invalid name",
+			algorithmName:        "Iceberg_2",
+			inputBody:            `{"enabled": true}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid algorithm name","code":"invalid_algorithm_name"}`,
+		},
+		{
+			name:                 "Invalid resources",
+			algorithmName:        "pov",
+			inputBody:            `{"memory": "lots", "enabled": true}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid resources","code":"invalid_resources"}`,
+		},
+		{
+			name:                 "Negative resources",
+			algorithmName:        "pov",
+			inputBody:            `{"cpu": "-250m", "enabled": true}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid resources","code":"invalid_resources"}`,
+		},
+		{
+			name:                 "Invalid JSON body",
+			algorithmName:        "pov",
+			inputBody:            `invalid JSON`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid request body","code":"invalid_body"}`,
+		},
+		{
+			name:                 "Service error",
+			algorithmName:        "pov",
+			inputBody:            `{"enabled": true}`,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func(s *mock_service.MockService) {
+				s.EXPECT().SaveAlgorithm(gomock.Any(), gomock.Any()).Return(nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			service := mock_service.NewMockService(ctrl)
+			if tc.mockBehavior != nil {
+				tc.mockBehavior(service)
+			}
+
+			log := slogdiscard.NewDiscardLogger()
+			handler := New(service, log)
+
+			// Test server
+			r := chi.NewRouter()
+			r.Put("/algorithms/{name}", handler.saveAlgorithm)
+
+			// Test request
+			req := httptest.NewRequest("PUT", "/algorithms/"+tc.algorithmName, bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// Assert response
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+// withRole authenticates every request as a caller of the role
+func withRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := &auth.Principal{Subject: "subject", Method: auth.MethodJWT, Role: role}
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
 func boolPtr(b bool) *bool {
 	return &b
 }
@@ -35,6 +35,52 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// ApplyBatch mocks base method.
+func (m *MockService) ApplyBatch(ctx context.Context, batch *models.AlgoBatch) ([]*models.AlgoStatuses, []error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBatch", ctx, batch)
+	ret0, _ := ret[0].([]*models.AlgoStatuses)
+	ret1, _ := ret[1].([]error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ApplyBatch indicates an expected call of ApplyBatch.
+func (mr *MockServiceMockRecorder) ApplyBatch(ctx, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBatch", reflect.TypeOf((*MockService)(nil).ApplyBatch), ctx, batch)
+}
+
+// ListAlgorithms mocks base method.
+func (m *MockService) ListAlgorithms(ctx context.Context) ([]models.Algorithm, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAlgorithms", ctx)
+	ret0, _ := ret[0].([]models.Algorithm)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAlgorithms indicates an expected call of ListAlgorithms.
+func (mr *MockServiceMockRecorder) ListAlgorithms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAlgorithms", reflect.TypeOf((*MockService)(nil).ListAlgorithms), ctx)
+}
+
+// SaveAlgorithm mocks base method.
+func (m *MockService) SaveAlgorithm(ctx context.Context, algorithm *models.Algorithm) (*models.Algorithm, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAlgorithm", ctx, algorithm)
+	ret0, _ := ret[0].(*models.Algorithm)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveAlgorithm indicates an expected call of SaveAlgorithm.
+func (mr *MockServiceMockRecorder) SaveAlgorithm(ctx, algorithm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAlgorithm", reflect.TypeOf((*MockService)(nil).SaveAlgorithm), ctx, algorithm)
+}
+
 // UpdateStatuses mocks base method.
 func (m *MockService) UpdateStatuses(ctx context.Context, algoStatuses *models.AlgoStatuses) (*models.AlgoStatuses, error) {
 	m.ctrl.T.Helper()
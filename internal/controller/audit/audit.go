@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"sync-algo/internal/auth"
+	"sync-algo/internal/lib/apperr"
+	"sync-algo/internal/lib/queryparams"
+	"sync-algo/internal/lib/response"
+	"sync-algo/internal/models"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// defaultPageSize and maxPageSize bound the number of the events listed at once
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// Errors of the invalid requests, the service's errors are reported as they are
+var (
+	errInvalidLimit = apperr.Validation("invalid_limit", fmt.Sprintf("Limit must be from 1 to %d", maxPageSize))
+)
+
+// Service defines the interface for reading the audit log.
+//
+//go:generate mockgen -source=audit.go -destination=mock/mock.go -package=mock_service
+type Service interface {
+	ListEvents(ctx context.Context, query *models.AuditQuery) (*models.AuditPage, error)
+}
+
+type Handler struct {
+	service Service
+	log     *slog.Logger
+}
+
+func New(service Service, log *slog.Logger) *Handler {
+	return &Handler{
+		service: service,
+		log:     log,
+	}
+}
+
+// Register registers the API routes
+func (h *Handler) Register() func(r chi.Router) {
+	return func(r chi.Router) {
+		r.With(auth.Require(auth.PermissionReadAudit)).Get("/", h.listEvents)
+	}
+}
+
+// @Summary List audit events
+// @Description Get a page of the changes made through the API, the newest first.
+// @Description The next page is fetched by passing next_cursor back with the same filters.
+// @Tags audit
+// @Produce json
+// @Param client_id query int false "Client ID"
+// @Param from query string false "Earliest time of the events, RFC 3339"
+// @Param to query string false "Latest time of the events, RFC 3339"
+// @Param limit query int false "Page size, 100 by default" minimum(1) maximum(1000)
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} models.AuditPage
+// @Failure 400 {object} response.Response "Invalid filter or cursor"
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 422 {object} response.Response "Invalid limit"
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /audit/ [get]
+func (h *Handler) listEvents(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.audit.listEvents"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("listing audit events...")
+
+	query, appErr := parseAuditQuery(r.URL.Query())
+	if appErr != nil {
+		log.Error("invalid audit query", slog.String("error", appErr.Message))
+		response.Error(w, r, appErr)
+		return
+	}
+
+	page, err := h.service.ListEvents(r.Context(), query)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, page)
+}
+
+// parseAuditQuery reads the filters and the page of the events to list from the query string
+func parseAuditQuery(values url.Values) (*models.AuditQuery, *apperr.Error) {
+	query := &models.AuditQuery{
+		Limit:  defaultPageSize,
+		Cursor: values.Get("cursor"),
+	}
+
+	if value := values.Get("client_id"); value != "" {
+		clientID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || clientID <= 0 {
+			return nil, queryparams.InvalidFilter("client_id")
+		}
+		query.ClientID = clientID
+	}
+
+	err := queryparams.ParseTimes(values,
+		queryparams.TimeBound{Param: "from", Bound: &query.From},
+		queryparams.TimeBound{Param: "to", Bound: &query.To},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return nil, errInvalidLimit
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
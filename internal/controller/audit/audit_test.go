@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sync-algo/internal/auth"
+	mock_service "sync-algo/internal/controller/audit/mock"
+	"sync-algo/internal/lib/logger/handlers/slogdiscard"
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_listEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	handler := New(mockService, slogdiscard.NewDiscardLogger())
+
+	from := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 7, 31, 23, 59, 59, 0, time.UTC)
+	createdAt := time.Date(2024, 7, 17, 14, 30, 0, 0, time.UTC)
+
+	tt := []struct {
+		name                 string
+		role                 string
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:               "List events with defaults",
+			role:               auth.RoleAdmin,
+			url:                "/audit/",
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"events": [{"id": 42, "actor": "apikey:dashboard", "request_id": "host/abcdef-000001",
+				"action": "algorithms.toggle", "client_id": 1, "before": {"vwap": false}, "after": {"vwap": true},
+				"created_at": "2024-07-17T14:30:00Z"}], "next_cursor": "NDI"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ListEvents(gomock.Any(), &models.AuditQuery{Limit: defaultPageSize}).Return(&models.AuditPage{
+					Events: []models.AuditEvent{{
+						ID:        42,
+						Actor:     "apikey:dashboard",
+						RequestID: "host/abcdef-000001",
+						Action:    models.AuditAlgorithmsToggle,
+						ClientID:  1,
+						Before:    []byte(`{"vwap": false}`),
+						After:     []byte(`{"vwap": true}`),
+						CreatedAt: createdAt,
+					}},
+					NextCursor: "NDI",
+				}, nil)
+			},
+		},
+		{
+			name:                 "Filter by client and time range",
+			role:                 auth.RoleAdmin,
+			url:                  "/audit/?client_id=1&from=2024-07-01T00:00:00Z&to=2024-07-31T23:59:59Z&limit=10&cursor=NDI",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"events": []}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ListEvents(gomock.Any(), &models.AuditQuery{
+					ClientID: 1,
+					From:     &from,
+					To:       &to,
+					Limit:    10,
+					Cursor:   "NDI",
+				}).Return(&models.AuditPage{Events: []models.AuditEvent{}}, nil)
+			},
+		},
+		{
+			name:                 "Invalid client ID",
+			role:                 auth.RoleAdmin,
+			url:                  "/audit/?client_id=0",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid filter client_id","code":"invalid_filter"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid time",
+			role:                 auth.RoleAdmin,
+			url:                  "/audit/?from=yesterday",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid filter from","code":"invalid_filter"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Limit out of range",
+			role:                 auth.RoleAdmin,
+			url:                  "/audit/?limit=1001",
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: fmt.Sprintf(`{"status":"Error","error":"Limit must be from 1 to %d","code":"invalid_limit"}`, maxPageSize),
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid cursor",
+			role:                 auth.RoleAdmin,
+			url:                  "/audit/?cursor=bogus",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid cursor","code":"invalid_cursor"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ListEvents(gomock.Any(), &models.AuditQuery{Limit: defaultPageSize, Cursor: "bogus"}).
+					Return(nil, fmt.Errorf("storage.postgres.FetchAuditEvents: %w", storage.ErrInvalidCursor))
+			},
+		},
+		{
+			name:                 "Storage unavailable",
+			role:                 auth.RoleAdmin,
+			url:                  "/audit/",
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"Error","error":"Storage unavailable","code":"storage_unavailable"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ListEvents(gomock.Any(), gomock.Any()).
+					Return(nil, fmt.Errorf("storage.postgres.FetchAuditEvents: %w", storage.ErrUnavailable))
+			},
+		},
+		{
+			name:                 "Operator can't read audit log",
+			role:                 auth.RoleOperator,
+			url:                  "/audit/",
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"status":"Error","error":"Role operator is not allowed to read the audit log","code":"forbidden"}`,
+			mockBehavior:         func() {},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			r := chi.NewRouter()
+			r.Use(withRole(tc.role))
+			r.Route("/audit", handler.Register())
+
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func withRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := &auth.Principal{Subject: "subject", Method: auth.MethodJWT, Role: role}
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
@@ -2,26 +2,89 @@ package client
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
+	"sync-algo/internal/auth"
+	"sync-algo/internal/lib/apperr"
+	"sync-algo/internal/lib/bulk"
 	"sync-algo/internal/lib/logger/sl"
+	"sync-algo/internal/lib/queryparams"
 	"sync-algo/internal/lib/response"
 	"sync-algo/internal/models"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // Service defines the interface for client operations.
 type Service interface {
 	AddClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error)
-	UpdateClient(ctx context.Context, clientInfo *models.Client) error
+	UpdateClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error)
+	PatchClient(ctx context.Context, patch *models.ClientPatch) (*models.Client, error)
 	DeleteClient(ctx context.Context, clientID int) error
+	ListClients(ctx context.Context, query *models.ClientQuery) (*models.ClientPage, error)
+	GetClient(ctx context.Context, clientID int) (*models.Client, error)
+	GetAlgorithms(ctx context.Context, clientID int) (*models.AlgoStatuses, error)
+	ApplyBatch(ctx context.Context, batch *models.ClientBatch) ([]*models.Client, []error, error)
+	GetParams(ctx context.Context, clientID int, algorithm string) (*models.AlgoParams, error)
+	UpdateParams(ctx context.Context, algoParams *models.AlgoParams) (*models.AlgoParams, error)
 }
 
+// maxBatchItems limits the number of items in a single batch request
+const maxBatchItems = 1000
+
+// defaultPageSize and maxPageSize bound the number of the clients listed at once
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// sortKeys are the keys the clients can be listed by
+var sortKeys = map[string]bool{
+	models.ClientSortID:        true,
+	models.ClientSortName:      true,
+	models.ClientSortCreatedAt: true,
+	models.ClientSortPriority:  true,
+}
+
+// emptyValue is the ID of a client not set in the request
+const emptyValue = 0
+
+// anyRevision lets the update be applied whatever the client's current revision is
+const anyRevision = 0
+
+// maxParamsSize limits the size of the algorithm's parameters passed to its workload
+const maxParamsSize = 4096
+
+// paramNamePattern restricts the names of the algorithm's parameters to simple identifiers
+var paramNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Errors of the invalid requests, the service's errors are reported as they are
+var (
+	errInvalidBody      = apperr.BadRequest("invalid_body", "Invalid request body")
+	errInvalidClientID  = apperr.BadRequest("invalid_client_id", "Invalid client id")
+	errInvalidIfMatch   = apperr.BadRequest("invalid_if_match", "Invalid If-Match header")
+	errInvalidSort      = apperr.Validation("invalid_sort", "Unknown sort key")
+	errInvalidLimit     = apperr.Validation("invalid_limit", fmt.Sprintf("Limit must be from 1 to %d", maxPageSize))
+	errNameRequired     = apperr.Validation("name_required", "Client name is required")
+	errEmptyPatch       = apperr.Validation("empty_patch", "Nothing to update")
+	errInvalidResources = apperr.Validation("invalid_resources", "Invalid resources")
+	errInvalidOperation = apperr.Validation("invalid_operation", "Invalid operation")
+	errInvalidBatchSize = apperr.BadRequest("invalid_batch_size", fmt.Sprintf("Batch must contain from 1 to %d items", maxBatchItems))
+)
+
 // Handler handles HTTP requests related to clients.
 type Handler struct {
 	service Service
@@ -39,9 +102,27 @@ func New(service Service, log *slog.Logger) *Handler {
 // Register registers the client routes with a router.
 func (h *Handler) Register() func(r chi.Router) {
 	return func(r chi.Router) {
-		r.Post("/", h.addClient)
-		r.Put("/{id}", h.updateClient)
-		r.Delete("/{id}", h.deleteClient)
+		read := r.With(auth.Require(auth.PermissionRead))
+		read.Get("/", h.listClients)
+		read.Get("/{id}", h.getClient)
+		read.Get("/{id}/algorithms", h.getAlgorithms)
+		read.Get("/{id}/algorithms/{name}/params", h.getParams)
+
+		r.With(auth.Require(auth.PermissionWriteParams)).Put("/{id}/algorithms/{name}/params", h.updateParams)
+
+		write := r.With(auth.Require(auth.PermissionWriteClients))
+		write.Post("/", h.addClient)
+		write.Put("/{id}", h.updateClient)
+		write.Patch("/{id}", h.patchClient)
+
+		r.With(auth.Require(auth.PermissionDeleteClients)).Delete("/{id}", h.deleteClient)
+	}
+}
+
+// RegisterBatch registers the bulk routes, which live next to the /clients routes rather than under them.
+func (h *Handler) RegisterBatch() func(r chi.Router) {
+	return func(r chi.Router) {
+		r.With(auth.Require(auth.PermissionWriteClients)).Post("/clients:batch", h.batchClients)
 	}
 }
 
@@ -53,7 +134,14 @@ func (h *Handler) Register() func(r chi.Router) {
 // @Param request body models.Client true "Client information"
 // @Success 201 {object} models.Client
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 409 {object} response.Response "Client name already taken"
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage or cluster unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /clients/ [post]
 func (h *Handler) addClient(w http.ResponseWriter, r *http.Request) {
 	const op = "controller.client.addClient"
@@ -69,41 +157,57 @@ func (h *Handler) addClient(w http.ResponseWriter, r *http.Request) {
 	err := render.Decode(r, &clientInfo)
 	if err != nil {
 		log.Error("failed extract user info from request body", sl.Error(err))
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, response.Err("Invalid credentials"))
+		response.Error(w, r, errInvalidBody)
 		return
 	}
 
 	if clientInfo.ClientName == "" {
 		log.Error(`client's name is empty`)
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, response.Err("Invalid credentials"))
+		response.Error(w, r, errNameRequired)
+		return
+	}
+
+	if err := validateResources(&clientInfo); err != nil {
+		log.Error("invalid client resources", sl.Error(err))
+		response.Error(w, r, errInvalidResources)
 		return
 	}
 
 	client, err := h.service.AddClient(r.Context(), &clientInfo)
 	if err != nil {
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, response.Err("Internal error"))
+		response.Error(w, r, err)
 		return
 	}
 
 	log.Debug("client created successfully")
 
+	setETag(w, client)
 	render.Status(r, http.StatusCreated)
 	render.JSON(w, r, client)
 }
 
 // @Summary Update an existing client
-// @Description Update an existing client in the system
+// @Description Replace all fields of an existing client, the fields left out are reset.
+// @Description The update is applied only if the revision in If-Match, or in the body, is still current.
 // @Tags clients
 // @Accept json
 // @Produce json
 // @Param id path int true "Client ID"
+// @Param If-Match header string false "ETag of the client the update is based on"
 // @Param request body models.Client true "Updated client information"
 // @Success 200 {object} models.Client
+// @Header 200 {string} ETag "Revision of the updated client"
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response "Client name already taken"
+// @Failure 412 {object} response.Response "Client modified by another request"
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /clients/{id} [put]
 func (h *Handler) updateClient(w http.ResponseWriter, r *http.Request) {
 	const op = "controller.client.updateClient"
@@ -118,8 +222,14 @@ func (h *Handler) updateClient(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
 		log.Error("failed to extract client id from request params", sl.Error(err))
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, response.Err("Invalid client id"))
+		response.Error(w, r, errInvalidClientID)
+		return
+	}
+
+	revision, err := ifMatch(r)
+	if err != nil {
+		log.Error("failed to parse If-Match header", sl.Error(err))
+		response.Error(w, r, errInvalidIfMatch)
 		return
 	}
 
@@ -127,30 +237,136 @@ func (h *Handler) updateClient(w http.ResponseWriter, r *http.Request) {
 	err = render.Decode(r, &clientInfo)
 	if err != nil {
 		log.Error("failed to extract client info from request body", sl.Error(err))
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, response.Err("Invalid request body"))
+		response.Error(w, r, errInvalidBody)
 		return
 	}
 
 	if clientInfo.ClientName == "" {
 		log.Error("client name is required")
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, response.Err("Client name is required"))
+		response.Error(w, r, errNameRequired)
+		return
+	}
+
+	if err := validateResources(&clientInfo); err != nil {
+		log.Error("invalid client resources", sl.Error(err))
+		response.Error(w, r, errInvalidResources)
 		return
 	}
 
 	clientInfo.ID = int64(id)
+	if revision != anyRevision {
+		clientInfo.Revision = revision
+	}
 
-	err = h.service.UpdateClient(r.Context(), &clientInfo)
+	client, err := h.service.UpdateClient(r.Context(), &clientInfo)
 	if err != nil {
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, response.Err("Internal error"))
+		response.Error(w, r, err)
 		return
 	}
 
 	log.Debug("client updated successfully")
 
+	setETag(w, client)
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, client)
+}
+
+// @Summary Patch an existing client
+// @Description Change only the client's fields present in the body, the others are kept as they are.
+// @Description The patch is applied only if the revision in If-Match, or in the body, is still current.
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path int true "Client ID"
+// @Param If-Match header string false "ETag of the client the patch is based on"
+// @Param request body models.ClientPatch true "Fields to change"
+// @Success 200 {object} models.Client
+// @Header 200 {string} ETag "Revision of the patched client"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response "Client name already taken"
+// @Failure 412 {object} response.Response "Client modified by another request"
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/{id} [patch]
+func (h *Handler) patchClient(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.client.patchClient"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("patching client...")
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Error("failed to extract client id from request params", sl.Error(err))
+		response.Error(w, r, errInvalidClientID)
+		return
+	}
+
+	revision, err := ifMatch(r)
+	if err != nil {
+		log.Error("failed to parse If-Match header", sl.Error(err))
+		response.Error(w, r, errInvalidIfMatch)
+		return
+	}
+
+	var patch models.ClientPatch
+	err = render.Decode(r, &patch)
+	if err != nil {
+		log.Error("failed to extract patch from request body", sl.Error(err))
+		response.Error(w, r, errInvalidBody)
+		return
+	}
+
+	if patch.Empty() {
+		log.Error("nothing to update")
+		response.Error(w, r, errEmptyPatch)
+		return
+	}
+
+	if patch.ClientName != nil && *patch.ClientName == "" {
+		log.Error("client name is required")
+		response.Error(w, r, errNameRequired)
+		return
+	}
+
+	var resources models.Client
+	if patch.CPU != nil {
+		resources.CPU = *patch.CPU
+	}
+	if patch.Memory != nil {
+		resources.Memory = *patch.Memory
+	}
+	if err := validateResources(&resources); err != nil {
+		log.Error("invalid client resources", sl.Error(err))
+		response.Error(w, r, errInvalidResources)
+		return
+	}
+
+	patch.ID = int64(id)
+	if revision != anyRevision {
+		patch.Revision = revision
+	}
+
+	client, err := h.service.PatchClient(r.Context(), &patch)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	log.Debug("client patched successfully")
+
+	setETag(w, client)
 	render.Status(r, http.StatusOK)
+	render.JSON(w, r, client)
 }
 
 // @Summary Delete a client
@@ -161,7 +377,13 @@ func (h *Handler) updateClient(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Client ID"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage or cluster unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /clients/{id} [delete]
 func (h *Handler) deleteClient(w http.ResponseWriter, r *http.Request) {
 	const op = "controller.client.deleteClient"
@@ -176,15 +398,13 @@ func (h *Handler) deleteClient(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(chi.URLParam(r, "id"))
 	if err != nil {
 		log.Error("failed to extract client id from request params", sl.Error(err))
-		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, response.Err("Invalid client id"))
+		response.Error(w, r, errInvalidClientID)
 		return
 	}
 
 	err = h.service.DeleteClient(r.Context(), id)
 	if err != nil {
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, response.Err("Internal error"))
+		response.Error(w, r, err)
 		return
 	}
 
@@ -193,3 +413,525 @@ func (h *Handler) deleteClient(w http.ResponseWriter, r *http.Request) {
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, response.Ok("Client removed successfully"))
 }
+
+// @Summary List clients
+// @Description Get a page of the clients matching the filters, the next page is fetched by passing next_cursor back.
+// @Description The cursor is valid only for the same sort order.
+// @Tags clients
+// @Produce json
+// @Param name query string false "Substring of the client's name, case-insensitive"
+// @Param algorithm query string false "Algorithm enabled for the client"
+// @Param need_restart query bool false "Whether the client waits for a restart"
+// @Param created_from query string false "Earliest creation time, RFC 3339"
+// @Param created_to query string false "Latest creation time, RFC 3339"
+// @Param priority_min query number false "Lowest priority"
+// @Param priority_max query number false "Highest priority"
+// @Param sort query string false "Sort key, prefixed with - for the descending order" Enums(id, -id, name, -name, created_at, -created_at, priority, -priority)
+// @Param limit query int false "Page size, 50 by default" minimum(1) maximum(500)
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} models.ClientPage
+// @Failure 400 {object} response.Response "Invalid filter or cursor"
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 422 {object} response.Response "Invalid sort key or limit"
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/ [get]
+func (h *Handler) listClients(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.client.listClients"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("listing clients...")
+
+	query, appErr := parseClientQuery(r.URL.Query())
+	if appErr != nil {
+		log.Error("invalid client query", slog.String("error", appErr.Message))
+		response.Error(w, r, appErr)
+		return
+	}
+
+	page, err := h.service.ListClients(r.Context(), query)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, page)
+}
+
+// @Summary Get a client
+// @Description Get a client by its ID
+// @Tags clients
+// @Produce json
+// @Param id path int true "Client ID"
+// @Success 200 {object} models.Client
+// @Header 200 {string} ETag "Revision of the client"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/{id} [get]
+func (h *Handler) getClient(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.client.getClient"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("fetching client...")
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Error("failed to extract client id from request params", sl.Error(err))
+		response.Error(w, r, errInvalidClientID)
+		return
+	}
+
+	client, err := h.service.GetClient(r.Context(), id)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	setETag(w, client)
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, client)
+}
+
+// @Summary Get client's algorithm statuses
+// @Description Get statuses of the algorithms of a client and the observed state of their workloads in the cluster
+// @Tags clients
+// @Produce json
+// @Param id path int true "Client ID"
+// @Success 200 {object} models.AlgoStatuses
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/{id}/algorithms [get]
+func (h *Handler) getAlgorithms(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.client.getAlgorithms"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("fetching algorithm statuses...")
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Error("failed to extract client id from request params", sl.Error(err))
+		response.Error(w, r, errInvalidClientID)
+		return
+	}
+
+	statuses, err := h.service.GetAlgorithms(r.Context(), id)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, statuses)
+}
+
+// @Summary Get parameters of client's algorithm
+// @Description Get the parameters passed to the workload of the client's algorithm
+// @Tags clients
+// @Produce json
+// @Param id path int true "Client ID"
+// @Param name path string true "Algorithm name"
+// @Success 200 {object} models.AlgoParams
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/{id}/algorithms/{name}/params [get]
+func (h *Handler) getParams(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.client.getParams"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("fetching algorithm parameters...")
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Error("failed to extract client id from request params", sl.Error(err))
+		response.Error(w, r, errInvalidClientID)
+		return
+	}
+
+	params, err := h.service.GetParams(r.Context(), id, chi.URLParam(r, "name"))
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, params)
+}
+
+// @Summary Update parameters of client's algorithm
+// @Description Replace the parameters passed to the workload of the client's algorithm.
+// @Description Only the workload of this algorithm is redeployed with the new parameters.
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param id path int true "Client ID"
+// @Param name path string true "Algorithm name"
+// @Param request body models.AlgoParams true "Algorithm parameters"
+// @Success 200 {object} models.AlgoParams
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients/{id}/algorithms/{name}/params [put]
+func (h *Handler) updateParams(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.client.updateParams"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("updating algorithm parameters...")
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		log.Error("failed to extract client id from request params", sl.Error(err))
+		response.Error(w, r, errInvalidClientID)
+		return
+	}
+
+	var algoParams models.AlgoParams
+	err = render.Decode(r, &algoParams)
+	if err != nil {
+		log.Error("failed to extract parameters from request body", sl.Error(err))
+		response.Error(w, r, errInvalidBody)
+		return
+	}
+
+	if err := validateParams(algoParams.Params); err != nil {
+		log.Error("invalid algorithm parameters", sl.Error(err))
+		response.Error(w, r, apperr.Validation("invalid_params", fmt.Sprintf("Invalid parameters: %s", err)))
+		return
+	}
+
+	algoParams.ClientID = id
+	algoParams.Algorithm = chi.URLParam(r, "name")
+
+	params, err := h.service.UpdateParams(r.Context(), &algoParams)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	log.Debug("algorithm parameters updated successfully")
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, params)
+}
+
+// @Summary Create and update clients in bulk
+// @Description Apply many client creations and updates in a single transaction.
+// @Description An atomic batch is applied all-or-nothing: if any item is invalid, none is tried,
+// @Description the invalid items are reported as errors and the others as skipped.
+// @Description Otherwise the result of every item, including the invalid ones, is reported separately.
+// @Tags clients
+// @Accept json
+// @Produce json
+// @Param request body models.ClientBatch true "Client operations"
+// @Success 200 {object} models.ClientBatchResponse
+// @Failure 400 {object} response.Response "Invalid body or batch size"
+// @Failure 401 {object} response.Response "Missing or invalid credentials"
+// @Failure 403 {object} response.Response "Role not allowed to perform the operation"
+// @Failure 422 {object} models.ClientBatchResponse "Atomic batch with invalid items or rolled back"
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response "Storage unavailable"
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /clients:batch [post]
+func (h *Handler) batchClients(w http.ResponseWriter, r *http.Request) {
+	const op = "controller.client.batchClients"
+
+	log := h.log.With(
+		slog.String("op", op),
+		slog.String("req_id", middleware.GetReqID(r.Context())),
+	)
+
+	log.Debug("applying client batch...")
+
+	var batch models.ClientBatch
+	err := render.Decode(r, &batch)
+	if err != nil {
+		log.Error("failed to extract batch from request body", sl.Error(err))
+		response.Error(w, r, errInvalidBody)
+		return
+	}
+
+	if len(batch.Items) == 0 || len(batch.Items) > maxBatchItems {
+		log.Error("invalid batch size", slog.Int("items", len(batch.Items)))
+		response.Error(w, r, errInvalidBatchSize)
+		return
+	}
+
+	applied, results, err := bulk.Apply(batch.Items, batch.Atomic, validateBatchItem,
+		func(valid []models.ClientBatchItem) ([]*models.Client, []error, error) {
+			return h.service.ApplyBatch(r.Context(), &models.ClientBatch{Atomic: batch.Atomic, Items: valid})
+		},
+	)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	resp := models.ClientBatchResponse{
+		Applied: applied,
+		Results: make([]models.ClientBatchResult, len(results)),
+	}
+	for i, result := range results {
+		resp.Results[i] = models.ClientBatchResult{
+			Index:  i,
+			Status: result.Status,
+			Client: result.Value,
+			Error:  result.Error,
+			Code:   result.Code,
+		}
+	}
+
+	log.Debug("client batch applied", slog.Bool("applied", resp.Applied))
+
+	if !resp.Applied {
+		render.Status(r, http.StatusUnprocessableEntity)
+	} else {
+		render.Status(r, http.StatusOK)
+	}
+	render.JSON(w, r, resp)
+}
+
+// parseClientQuery reads the filters, the order and the page of the clients to list from the query string
+func parseClientQuery(values url.Values) (*models.ClientQuery, *apperr.Error) {
+	query := &models.ClientQuery{
+		Name:      values.Get("name"),
+		Algorithm: values.Get("algorithm"),
+		Sort:      models.ClientSortID,
+		Limit:     defaultPageSize,
+		Cursor:    values.Get("cursor"),
+	}
+
+	if value := values.Get("need_restart"); value != "" {
+		needRestart, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, queryparams.InvalidFilter("need_restart")
+		}
+		query.NeedRestart = &needRestart
+	}
+
+	err := queryparams.ParseTimes(values,
+		queryparams.TimeBound{Param: "created_from", Bound: &query.CreatedFrom},
+		queryparams.TimeBound{Param: "created_to", Bound: &query.CreatedTo},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	priorities := []struct {
+		param string
+		bound **float64
+	}{
+		{"priority_min", &query.PriorityMin},
+		{"priority_max", &query.PriorityMax},
+	}
+	for _, p := range priorities {
+		if value := values.Get(p.param); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(parsed) {
+				return nil, queryparams.InvalidFilter(p.param)
+			}
+			*p.bound = &parsed
+		}
+	}
+
+	if value := values.Get("sort"); value != "" {
+		query.Desc = strings.HasPrefix(value, "-")
+		query.Sort = strings.TrimPrefix(value, "-")
+		if !sortKeys[query.Sort] {
+			return nil, errInvalidSort
+		}
+	}
+
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return nil, errInvalidLimit
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+// validateBatchItem checks the client batch item, returning the reason it is invalid
+func validateBatchItem(item *models.ClientBatchItem) *apperr.Error {
+	switch item.Op {
+	case models.BatchCreate:
+	case models.BatchUpdate:
+		if item.Client.ID == emptyValue {
+			return errInvalidClientID
+		}
+	default:
+		return errInvalidOperation
+	}
+
+	if item.Client.ClientName == "" {
+		return errNameRequired
+	}
+
+	if err := validateResources(&item.Client); err != nil {
+		return errInvalidResources
+	}
+
+	return nil
+}
+
+// setETag reports the client's revision, which the later updates pass back in If-Match
+func setETag(w http.ResponseWriter, client *models.Client) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(client.Revision)+`"`)
+}
+
+// ifMatch extracts the revision the update is based on from the If-Match header,
+// anyRevision if the header is missing or matches any revision
+func ifMatch(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return anyRevision, nil
+	}
+
+	// The revision is compared as it is, so the weak ETags match as well
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, fmt.Errorf("malformed ETag %q", value)
+	}
+
+	revision, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || revision <= 0 {
+		return 0, fmt.Errorf("invalid revision %q", value)
+	}
+
+	return revision, nil
+}
+
+// validateResources checks that client's CPU and memory are valid positive Kubernetes quantities.
+func validateResources(clientInfo *models.Client) error {
+	if clientInfo.CPU != "" {
+		if err := validateQuantity(clientInfo.CPU); err != nil {
+			return fmt.Errorf("invalid cpu %q: %w", clientInfo.CPU, err)
+		}
+	}
+
+	if clientInfo.Memory != "" {
+		if err := validateQuantity(clientInfo.Memory); err != nil {
+			return fmt.Errorf("invalid memory %q: %w", clientInfo.Memory, err)
+		}
+	}
+
+	return nil
+}
+
+// validateQuantity checks the resource is a valid Kubernetes quantity, which is also positive
+func validateQuantity(value string) error {
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return err
+	}
+	if q.Sign() <= 0 {
+		return errors.New("must be positive")
+	}
+
+	return nil
+}
+
+// validateParams checks the algorithm's parameters are a JSON object of bounded size,
+// and the parameters known to the algorithms have the expected types
+func validateParams(raw json.RawMessage) error {
+	if len(raw) > maxParamsSize {
+		return fmt.Errorf("must not exceed %d bytes", maxParamsSize)
+	}
+
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &params); err != nil || params == nil {
+		return errors.New("must be a JSON object")
+	}
+
+	for name, value := range params {
+		if !paramNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid name %q", name)
+		}
+
+		if err := validateParam(name, value); err != nil {
+			return fmt.Errorf("%s %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateParam checks the value of the parameter known to the algorithms, the unknown ones are passed as is
+func validateParam(name string, value json.RawMessage) error {
+	switch name {
+	case "slice_interval":
+		var interval string
+		if err := json.Unmarshal(value, &interval); err != nil {
+			return errors.New("must be a duration string")
+		}
+		if d, err := time.ParseDuration(interval); err != nil || d <= 0 {
+			return errors.New("must be a positive duration")
+		}
+	case "participation_rate":
+		var rate float64
+		if err := json.Unmarshal(value, &rate); err != nil || rate <= 0 || rate > 1 {
+			return errors.New("must be a number in (0, 1]")
+		}
+	case "venues":
+		var venues []string
+		if err := json.Unmarshal(value, &venues); err != nil {
+			return errors.New("must be an array of strings")
+		}
+		for _, venue := range venues {
+			if venue == "" {
+				return errors.New("must not contain empty venues")
+			}
+		}
+	}
+
+	return nil
+}
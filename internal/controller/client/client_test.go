@@ -2,21 +2,105 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"sync-algo/internal/auth"
 	mock_service "sync-algo/internal/controller/client/mock"
 	"sync-algo/internal/lib/logger/handlers/slogdiscard"
 	"sync-algo/internal/models"
+	service "sync-algo/internal/service/client"
+	"sync-algo/internal/storage"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestHandler_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	tt := []struct {
+		name                 string
+		role                 string
+		method               string
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "Viewer reads client",
+			role:                 auth.RoleViewer,
+			method:               "GET",
+			url:                  "/clients/1",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"id":1,"client_name":"clientName","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetClient(gomock.Any(), 1).Return(&models.Client{ID: 1, ClientName: "clientName"}, nil)
+			},
+		},
+		{
+			name:                 "Viewer can't change parameters",
+			role:                 auth.RoleViewer,
+			method:               "PUT",
+			url:                  "/clients/1/algorithms/vwap/params",
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"status":"Error","error":"Role viewer is not allowed to change algorithm parameters","code":"forbidden"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Operator can't delete client",
+			role:                 auth.RoleOperator,
+			method:               "DELETE",
+			url:                  "/clients/1",
+			expectedStatusCode:   http.StatusForbidden,
+			expectedResponseBody: `{"status":"Error","error":"Role operator is not allowed to delete clients","code":"forbidden"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Admin deletes client",
+			role:                 auth.RoleAdmin,
+			method:               "DELETE",
+			url:                  "/clients/1",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"status":"OK","message":"Client removed successfully"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().DeleteClient(gomock.Any(), 1).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			r := chi.NewRouter()
+			r.Use(withRole(tc.role))
+			r.Route("/clients", handler.Register())
+
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
 func TestHandler_addClient(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -54,21 +138,58 @@ func TestHandler_addClient(t *testing.T) {
 			name:                 "Invalid JSON body",
 			inputBody:            `{"client_name":}`,
 			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: `{"status":"Error","error":"Invalid credentials"}`,
+			expectedResponseBody: `{"status":"Error","error":"Invalid request body","code":"invalid_body"}`,
 			mockBehavior:         func() {},
 		},
 		{
 			name:                 "Empty client name",
 			inputBody:            `{"client_name": ""}`,
-			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: `{"status":"Error","error":"Invalid credentials"}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Client name is required","code":"name_required"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid cpu",
+			inputBody:            `{"client_name": "clientName", "cpu": "2 cores"}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid resources","code":"invalid_resources"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Negative cpu",
+			inputBody:            `{"client_name": "clientName", "cpu": "-500m"}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid resources","code":"invalid_resources"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Zero memory",
+			inputBody:            `{"client_name": "clientName", "memory": "0"}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid resources","code":"invalid_resources"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid memory",
+			inputBody:            `{"client_name": "clientName", "cpu": "500m", "memory": "4 GB"}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid resources","code":"invalid_resources"}`,
 			mockBehavior:         func() {},
 		},
+		{
+			name:                 "Duplicate client name",
+			inputBody:            `{"client_name": "ClientName"}`,
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"status":"Error","error":"Client already exists","code":"client_exists"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().AddClient(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrExists))
+			},
+		},
 		{
 			name:                 "Service error",
 			inputBody:            `{"client_name": "clientName"}`,
 			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: `{"status":"Error","error":"Internal error"}`,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
 			mockBehavior: func() {
 				mockService.EXPECT().AddClient(gomock.Any(), gomock.Any()).Return(nil, errors.New("service error"))
 			},
@@ -92,6 +213,276 @@ func TestHandler_addClient(t *testing.T) {
 	}
 }
 
+func TestHandler_updateClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Put("/clients/{id}", handler.updateClient)
+
+	tt := []struct {
+		name                 string
+		url                  string
+		ifMatch              string
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "Update client successfully",
+			url:                  "/clients/1",
+			inputBody:            `{"client_name": "clientName", "cpu": "500m", "memory": "512Mi"}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"id":1,"client_name":"clientName","cpu":"500m","memory":"512Mi","restart_error":"failed to delete pod","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().UpdateClient(gomock.Any(), &models.Client{
+					ID:         1,
+					ClientName: "clientName",
+					CPU:        "500m",
+					Memory:     "512Mi",
+				}).Return(&models.Client{
+					ID:           1,
+					ClientName:   "clientName",
+					CPU:          "500m",
+					Memory:       "512Mi",
+					RestartError: "failed to delete pod",
+				}, nil)
+			},
+		},
+		{
+			name:                 "Stale revision",
+			url:                  "/clients/1",
+			ifMatch:              `"2"`,
+			inputBody:            `{"client_name": "clientName"}`,
+			expectedStatusCode:   http.StatusPreconditionFailed,
+			expectedResponseBody: `{"status":"Error","error":"Client was modified by another request","code":"stale_revision"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().UpdateClient(gomock.Any(), &models.Client{
+					ID:         1,
+					ClientName: "clientName",
+					Revision:   2,
+				}).Return(nil, fmt.Errorf("storage: %w", storage.ErrStaleRevision))
+			},
+		},
+		{
+			name:                 "Invalid If-Match header",
+			url:                  "/clients/1",
+			ifMatch:              "2",
+			inputBody:            `{"client_name": "clientName"}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid If-Match header","code":"invalid_if_match"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid client ID in URL",
+			url:                  "/clients/invalid",
+			inputBody:            `{"client_name": "clientName"}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid client id","code":"invalid_client_id"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid resources",
+			url:                  "/clients/1",
+			inputBody:            `{"client_name": "clientName", "memory": "4 GB"}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid resources","code":"invalid_resources"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Duplicate client name",
+			url:                  "/clients/1",
+			inputBody:            `{"client_name": "Taken"}`,
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"status":"Error","error":"Client already exists","code":"client_exists"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().UpdateClient(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrExists))
+			},
+		},
+		{
+			name:                 "Service error",
+			url:                  "/clients/1",
+			inputBody:            `{"client_name": "clientName"}`,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().UpdateClient(gomock.Any(), gomock.Any()).Return(nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("PUT", tc.url, bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_patchClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Patch("/clients/{id}", handler.patchClient)
+
+	priority := 0.5
+	name := "clientName"
+
+	tt := []struct {
+		name                 string
+		url                  string
+		ifMatch              string
+		inputBody            string
+		expectedStatusCode   int
+		expectedETag         string
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "Patch client successfully",
+			url:                  "/clients/1",
+			ifMatch:              `W/"2"`,
+			inputBody:            `{"priority": 0.5}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedETag:         `"3"`,
+			expectedResponseBody: `{"id":1,"client_name":"clientName","image":"image","cpu":"500m","priority":0.5,"revision":3,"spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().PatchClient(gomock.Any(), &models.ClientPatch{
+					ID:       1,
+					Priority: &priority,
+					Revision: 2,
+				}).Return(&models.Client{
+					ID:         1,
+					ClientName: "clientName",
+					Image:      "image",
+					CPU:        "500m",
+					Priority:   0.5,
+					Revision:   3,
+				}, nil)
+			},
+		},
+		{
+			name:                 "Revision in body",
+			url:                  "/clients/1",
+			inputBody:            `{"client_name": "clientName", "revision": 4}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedETag:         `"5"`,
+			expectedResponseBody: `{"id":1,"client_name":"clientName","revision":5,"spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().PatchClient(gomock.Any(), &models.ClientPatch{
+					ID:         1,
+					ClientName: &name,
+					Revision:   4,
+				}).Return(&models.Client{ID: 1, ClientName: "clientName", Revision: 5}, nil)
+			},
+		},
+		{
+			name:                 "Stale revision",
+			url:                  "/clients/1",
+			ifMatch:              `"1"`,
+			inputBody:            `{"priority": 0.5}`,
+			expectedStatusCode:   http.StatusPreconditionFailed,
+			expectedResponseBody: `{"status":"Error","error":"Client was modified by another request","code":"stale_revision"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().PatchClient(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrStaleRevision))
+			},
+		},
+		{
+			name:                 "Client not found",
+			url:                  "/clients/2",
+			inputBody:            `{"priority": 0.5}`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"status":"Error","error":"Client not found","code":"client_not_found"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().PatchClient(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrUserNotFound))
+			},
+		},
+		{
+			name:                 "Duplicate client name",
+			url:                  "/clients/1",
+			inputBody:            `{"client_name": "Taken"}`,
+			expectedStatusCode:   http.StatusConflict,
+			expectedResponseBody: `{"status":"Error","error":"Client already exists","code":"client_exists"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().PatchClient(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrExists))
+			},
+		},
+		{
+			name:                 "Invalid If-Match header",
+			url:                  "/clients/1",
+			ifMatch:              `"abc"`,
+			inputBody:            `{"priority": 0.5}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid If-Match header","code":"invalid_if_match"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Empty patch",
+			url:                  "/clients/1",
+			inputBody:            `{"revision": 2}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Nothing to update","code":"empty_patch"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Empty client name",
+			url:                  "/clients/1",
+			inputBody:            `{"client_name": ""}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Client name is required","code":"name_required"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid resources",
+			url:                  "/clients/1",
+			inputBody:            `{"cpu": "half"}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid resources","code":"invalid_resources"}`,
+			mockBehavior:         func() {},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("PATCH", tc.url, bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedETag, w.Header().Get("ETag"))
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
 func TestHandler_deleteClient(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -123,14 +514,41 @@ func TestHandler_deleteClient(t *testing.T) {
 			name:                 "Invalid client ID in URL",
 			url:                  "/clients/invalid",
 			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: `{"status":"Error","error":"Invalid client id"}`,
+			expectedResponseBody: `{"status":"Error","error":"Invalid client id","code":"invalid_client_id"}`,
 			mockBehavior:         func() {},
 		},
+		{
+			name:                 "Client not found",
+			url:                  "/clients/2",
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"status":"Error","error":"Client not found","code":"client_not_found"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().DeleteClient(gomock.Any(), 2).Return(fmt.Errorf("storage: %w", storage.ErrUserNotFound))
+			},
+		},
+		{
+			name:                 "Cluster unavailable",
+			url:                  "/clients/1",
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"Error","error":"Cluster unavailable","code":"cluster_unavailable"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().DeleteClient(gomock.Any(), 1).Return(fmt.Errorf("%w: %w", service.ErrCluster, errors.New("kubernetes error")))
+			},
+		},
+		{
+			name:                 "Storage unavailable",
+			url:                  "/clients/1",
+			expectedStatusCode:   http.StatusServiceUnavailable,
+			expectedResponseBody: `{"status":"Error","error":"Storage unavailable","code":"storage_unavailable"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().DeleteClient(gomock.Any(), 1).Return(fmt.Errorf("storage: %w: %w", storage.ErrUnavailable, errors.New("connection refused")))
+			},
+		},
 		{
 			name:                 "Service error",
 			url:                  "/clients/1",
 			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: `{"status":"Error","error":"Internal error"}`,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
 			mockBehavior: func() {
 				mockService.EXPECT().DeleteClient(gomock.Any(), 1).Return(errors.New("internal service error"))
 			},
@@ -151,3 +569,612 @@ func TestHandler_deleteClient(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_listClients(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Get("/clients", handler.listClients)
+
+	needRestart := true
+	createdFrom := time.Date(2024, 7, 1, 5, 0, 0, 0, time.UTC)
+	priorityMin := 0.5
+
+	tt := []struct {
+		name                 string
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "List clients successfully",
+			url:                  "/clients",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"clients":[{"id":1,"client_name":"clientA","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"},{"id":2,"client_name":"clientB","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}],"next_cursor":"next","total":3}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ListClients(gomock.Any(), &models.ClientQuery{
+					Sort:  models.ClientSortID,
+					Limit: defaultPageSize,
+				}).Return(&models.ClientPage{
+					Clients: []models.Client{
+						{ID: 1, ClientName: "clientA"},
+						{ID: 2, ClientName: "clientB"},
+					},
+					NextCursor: "next",
+					Total:      3,
+				}, nil)
+			},
+		},
+		{
+			name:                 "Filters, sort and cursor",
+			url:                  "/clients?name=acme&algorithm=vwap&need_restart=true&created_from=2024-07-01T08:00:00%2B03:00&priority_min=0.5&sort=-priority&limit=10&cursor=abc",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"clients":[],"total":0}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ListClients(gomock.Any(), &models.ClientQuery{
+					Name:        "acme",
+					Algorithm:   "vwap",
+					NeedRestart: &needRestart,
+					CreatedFrom: &createdFrom,
+					PriorityMin: &priorityMin,
+					Sort:        models.ClientSortPriority,
+					Desc:        true,
+					Limit:       10,
+					Cursor:      "abc",
+				}).Return(&models.ClientPage{Clients: []models.Client{}}, nil)
+			},
+		},
+		{
+			name:                 "Invalid filter",
+			url:                  "/clients?created_to=yesterday",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid filter created_to","code":"invalid_filter"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Unknown sort key",
+			url:                  "/clients?sort=-image",
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Unknown sort key","code":"invalid_sort"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Limit out of range",
+			url:                  "/clients?limit=1000",
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Limit must be from 1 to 500","code":"invalid_limit"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid cursor",
+			url:                  "/clients?sort=name&cursor=abc",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid cursor","code":"invalid_cursor"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ListClients(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrInvalidCursor))
+			},
+		},
+		{
+			name:                 "Service error",
+			url:                  "/clients",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ListClients(gomock.Any(), gomock.Any()).Return(nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("GET", tc.url, nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Get("/clients/{id}", handler.getClient)
+
+	tt := []struct {
+		name                 string
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "Get client successfully",
+			url:                  "/clients/1",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"id":1,"client_name":"clientName","cpu":"500m","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetClient(gomock.Any(), 1).Return(&models.Client{ID: 1, ClientName: "clientName", CPU: "500m"}, nil)
+			},
+		},
+		{
+			name:                 "Invalid client ID in URL",
+			url:                  "/clients/invalid",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid client id","code":"invalid_client_id"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Client not found",
+			url:                  "/clients/2",
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"status":"Error","error":"Client not found","code":"client_not_found"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetClient(gomock.Any(), 2).Return(nil, fmt.Errorf("storage: %w", storage.ErrUserNotFound))
+			},
+		},
+		{
+			name:                 "Service error",
+			url:                  "/clients/1",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetClient(gomock.Any(), 1).Return(nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("GET", tc.url, nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getAlgorithms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Get("/clients/{id}/algorithms", handler.getAlgorithms)
+
+	tt := []struct {
+		name                 string
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "Get algorithm statuses successfully",
+			url:                  "/clients/1/algorithms",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"client_id":1,"algorithms":{"vwap":true,"twap":false,"hft":false}}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetAlgorithms(gomock.Any(), 1).Return(&models.AlgoStatuses{
+					ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": false},
+				}, nil)
+			},
+		},
+		{
+			name:               "Get algorithm statuses with workload states",
+			url:                "/clients/1/algorithms",
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"client_id":1,"algorithms":{"vwap":true},"workloads":{"vwap":{"pod":"client-1-vwap",
+				"phase":"Running","reason":"CrashLoopBackOff","restart_count":4,"version":2,
+				"last_transition_at":"2024-07-17T14:30:00Z"}}}`,
+			mockBehavior: func() {
+				transition := time.Date(2024, 7, 17, 14, 30, 0, 0, time.UTC)
+				mockService.EXPECT().GetAlgorithms(gomock.Any(), 1).Return(&models.AlgoStatuses{
+					ClientID:   1,
+					Algorithms: map[string]bool{"vwap": true},
+					Workloads: map[string]models.WorkloadState{"vwap": {
+						Pod: "client-1-vwap", Phase: "Running", Reason: models.ReasonCrashLoopBackOff,
+						RestartCount: 4, Version: 2, LastTransitionAt: &transition,
+					}},
+				}, nil)
+			},
+		},
+		{
+			name:                 "Invalid client ID in URL",
+			url:                  "/clients/invalid/algorithms",
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid client id","code":"invalid_client_id"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Client not found",
+			url:                  "/clients/2/algorithms",
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"status":"Error","error":"Client not found","code":"client_not_found"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetAlgorithms(gomock.Any(), 2).Return(nil, fmt.Errorf("storage: %w", storage.ErrUserNotFound))
+			},
+		},
+		{
+			name:                 "Service error",
+			url:                  "/clients/1/algorithms",
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetAlgorithms(gomock.Any(), 1).Return(nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("GET", tc.url, nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_getParams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Get("/clients/{id}/algorithms/{name}/params", handler.getParams)
+
+	tt := []struct {
+		name                 string
+		url                  string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "Get parameters successfully",
+			url:                  "/clients/1/algorithms/twap/params",
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"client_id":1,"algorithm":"twap","params":{"slice_interval":"30s"}}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetParams(gomock.Any(), 1, "twap").Return(&models.AlgoParams{
+					ClientID: 1, Algorithm: "twap", Params: json.RawMessage(`{"slice_interval":"30s"}`),
+				}, nil)
+			},
+		},
+		{
+			name:                 "Unknown algorithm",
+			url:                  "/clients/1/algorithms/pov/params",
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"status":"Error","error":"Algorithm not found","code":"algorithm_not_found"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetParams(gomock.Any(), 1, "pov").Return(nil, fmt.Errorf("storage: %w", storage.ErrAlgorithmNotFound))
+			},
+		},
+		{
+			name:                 "Client not found",
+			url:                  "/clients/2/algorithms/twap/params",
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"status":"Error","error":"Client not found","code":"client_not_found"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().GetParams(gomock.Any(), 2, "twap").Return(nil, fmt.Errorf("storage: %w", storage.ErrUserNotFound))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("GET", tc.url, nil)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_updateParams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Put("/clients/{id}/algorithms/{name}/params", handler.updateParams)
+
+	tt := []struct {
+		name                 string
+		url                  string
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:                 "Update parameters successfully",
+			url:                  "/clients/1/algorithms/twap/params",
+			inputBody:            `{"params": {"slice_interval": "30s", "participation_rate": 0.1, "venues": ["XNYS", "XNAS"], "urgency": "high"}}`,
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: `{"client_id":1,"algorithm":"twap","params":{"slice_interval":"30s","participation_rate":0.1,"venues":["XNYS","XNAS"],"urgency":"high"}}`,
+			mockBehavior: func() {
+				mockService.EXPECT().UpdateParams(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, algoParams *models.AlgoParams) (*models.AlgoParams, error) {
+						assert.Equal(t, 1, algoParams.ClientID)
+						assert.Equal(t, "twap", algoParams.Algorithm)
+						return algoParams, nil
+					})
+			},
+		},
+		{
+			name:                 "Parameters are not an object",
+			url:                  "/clients/1/algorithms/twap/params",
+			inputBody:            `{"params": ["30s"]}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid parameters: must be a JSON object","code":"invalid_params"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid parameter name",
+			url:                  "/clients/1/algorithms/twap/params",
+			inputBody:            `{"params": {"Slice Interval": "30s"}}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid parameters: invalid name \"Slice Interval\"","code":"invalid_params"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid participation rate",
+			url:                  "/clients/1/algorithms/pov/params",
+			inputBody:            `{"params": {"participation_rate": 1.5}}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid parameters: participation_rate must be a number in (0, 1]","code":"invalid_params"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid slice interval",
+			url:                  "/clients/1/algorithms/twap/params",
+			inputBody:            `{"params": {"slice_interval": "soon"}}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"status":"Error","error":"Invalid parameters: slice_interval must be a positive duration","code":"invalid_params"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Unknown algorithm",
+			url:                  "/clients/1/algorithms/pov/params",
+			inputBody:            `{"params": {}}`,
+			expectedStatusCode:   http.StatusNotFound,
+			expectedResponseBody: `{"status":"Error","error":"Algorithm not found","code":"algorithm_not_found"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().UpdateParams(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("storage: %w", storage.ErrAlgorithmNotFound))
+			},
+		},
+		{
+			name:                 "Service error",
+			url:                  "/clients/1/algorithms/twap/params",
+			inputBody:            `{"params": {}}`,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().UpdateParams(gomock.Any(), gomock.Any()).Return(nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("PUT", tc.url, bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_batchClients(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mock_service.NewMockService(ctrl)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := New(mockService, logger)
+
+	r := chi.NewRouter()
+	r.Use(withRole(auth.RoleAdmin))
+	r.Group(handler.RegisterBatch())
+
+	tt := []struct {
+		name                 string
+		inputBody            string
+		expectedStatusCode   int
+		expectedResponseBody string
+		mockBehavior         func()
+	}{
+		{
+			name:               "Apply batch successfully",
+			inputBody:          `{"atomic": true, "items": [{"op": "create", "client": {"client_name": "clientA"}}, {"op": "update", "client": {"id": 2, "client_name": "clientB"}}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"applied":true,"results":[` +
+				`{"index":0,"status":"ok","client":{"id":1,"client_name":"clientA","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}},` +
+				`{"index":1,"status":"ok","client":{"id":2,"client_name":"clientB","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}}]}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ApplyBatch(gomock.Any(), &models.ClientBatch{
+					Atomic: true,
+					Items: []models.ClientBatchItem{
+						{Op: models.BatchCreate, Client: models.Client{ClientName: "clientA"}},
+						{Op: models.BatchUpdate, Client: models.Client{ID: 2, ClientName: "clientB"}},
+					},
+				}).Return([]*models.Client{
+					{ID: 1, ClientName: "clientA"},
+					{ID: 2, ClientName: "clientB"},
+				}, []error{nil, nil}, nil)
+			},
+		},
+		{
+			name:               "Report every item separately",
+			inputBody:          `{"items": [{"op": "update", "client": {"id": 5, "client_name": "clientA"}}, {"op": "create", "client": {"client_name": "clientB"}}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"applied":true,"results":[` +
+				`{"index":0,"status":"error","error":"Client not found","code":"client_not_found"},` +
+				`{"index":1,"status":"ok","client":{"id":1,"client_name":"clientB","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}}]}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ApplyBatch(gomock.Any(), gomock.Any()).Return(
+					[]*models.Client{nil, {ID: 1, ClientName: "clientB"}},
+					[]error{storage.ErrUserNotFound, nil},
+					nil,
+				)
+			},
+		},
+		{
+			name:               "Roll back atomic batch",
+			inputBody:          `{"atomic": true, "items": [{"op": "create", "client": {"client_name": "clientA"}}, {"op": "update", "client": {"id": 5, "client_name": "clientB"}}, {"op": "create", "client": {"client_name": "clientC"}}]}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"applied":false,"results":[` +
+				`{"index":0,"status":"rolled_back"},` +
+				`{"index":1,"status":"error","error":"Client not found","code":"client_not_found"},` +
+				`{"index":2,"status":"skipped"}]}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ApplyBatch(gomock.Any(), gomock.Any()).Return(
+					[]*models.Client{{ID: 1, ClientName: "clientA"}, nil, nil},
+					[]error{nil, storage.ErrUserNotFound, nil},
+					fmt.Errorf("storage: %w", storage.ErrBatchAborted),
+				)
+			},
+		},
+		{
+			name:                 "Invalid JSON body",
+			inputBody:            `{"items":}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Invalid request body","code":"invalid_body"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Empty batch",
+			inputBody:            `{"items": []}`,
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"status":"Error","error":"Batch must contain from 1 to 1000 items","code":"invalid_batch_size"}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Unknown operation",
+			inputBody:            `{"atomic": true, "items": [{"op": "delete", "client": {"id": 1, "client_name": "clientA"}}]}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"applied":false,"results":[{"index":0,"status":"error","error":"Invalid operation","code":"invalid_operation"}]}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Update without client id",
+			inputBody:            `{"atomic": true, "items": [{"op": "create", "client": {"client_name": "clientA"}}, {"op": "update", "client": {"client_name": "clientB"}}]}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"applied":false,"results":[{"index":0,"status":"skipped"},{"index":1,"status":"error","error":"Invalid client id","code":"invalid_client_id"}]}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:                 "Invalid resources",
+			inputBody:            `{"atomic": true, "items": [{"op": "create", "client": {"client_name": "clientA", "cpu": "2 cores"}}]}`,
+			expectedStatusCode:   http.StatusUnprocessableEntity,
+			expectedResponseBody: `{"applied":false,"results":[{"index":0,"status":"error","error":"Invalid resources","code":"invalid_resources"}]}`,
+			mockBehavior:         func() {},
+		},
+		{
+			name:               "Report invalid items of non-atomic batch separately",
+			inputBody:          `{"items": [{"op": "update", "client": {"client_name": "clientA"}}, {"op": "create", "client": {"client_name": "clientB"}}, {"op": "delete", "client": {"id": 1}}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"applied":true,"results":[` +
+				`{"index":0,"status":"error","error":"Invalid client id","code":"invalid_client_id"},` +
+				`{"index":1,"status":"ok","client":{"id":1,"client_name":"clientB","spawned_at":"0001-01-01T00:00:00Z","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}},` +
+				`{"index":2,"status":"error","error":"Invalid operation","code":"invalid_operation"}]}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ApplyBatch(gomock.Any(), &models.ClientBatch{
+					Items: []models.ClientBatchItem{
+						{Op: models.BatchCreate, Client: models.Client{ClientName: "clientB"}},
+					},
+				}).Return([]*models.Client{{ID: 1, ClientName: "clientB"}}, []error{nil}, nil)
+			},
+		},
+		{
+			name:               "Non-atomic batch without valid items",
+			inputBody:          `{"items": [{"op": "create", "client": {"client_name": "clientA", "cpu": "2 cores"}}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedResponseBody: `{"applied":true,"results":[` +
+				`{"index":0,"status":"error","error":"Invalid resources","code":"invalid_resources"}]}`,
+			mockBehavior: func() {},
+		},
+		{
+			name:                 "Service error",
+			inputBody:            `{"items": [{"op": "create", "client": {"client_name": "clientA"}}]}`,
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"status":"Error","error":"Internal error","code":"internal"}`,
+			mockBehavior: func() {
+				mockService.EXPECT().ApplyBatch(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("internal service error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mockBehavior()
+
+			req := httptest.NewRequest("POST", "/clients:batch", bytes.NewBufferString(tc.inputBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.JSONEq(t, tc.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+// withRole authenticates every request as a caller of the role
+func withRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := &auth.Principal{Subject: "subject", Method: auth.MethodJWT, Role: role}
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
@@ -50,6 +50,22 @@ func (mr *MockServiceMockRecorder) AddClient(ctx, clientInfo interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClient", reflect.TypeOf((*MockService)(nil).AddClient), ctx, clientInfo)
 }
 
+// ApplyBatch mocks base method.
+func (m *MockService) ApplyBatch(ctx context.Context, batch *models.ClientBatch) ([]*models.Client, []error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBatch", ctx, batch)
+	ret0, _ := ret[0].([]*models.Client)
+	ret1, _ := ret[1].([]error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ApplyBatch indicates an expected call of ApplyBatch.
+func (mr *MockServiceMockRecorder) ApplyBatch(ctx, batch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBatch", reflect.TypeOf((*MockService)(nil).ApplyBatch), ctx, batch)
+}
+
 // DeleteClient mocks base method.
 func (m *MockService) DeleteClient(ctx context.Context, clientID int) error {
 	m.ctrl.T.Helper()
@@ -64,12 +80,88 @@ func (mr *MockServiceMockRecorder) DeleteClient(ctx, clientID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClient", reflect.TypeOf((*MockService)(nil).DeleteClient), ctx, clientID)
 }
 
+// GetAlgorithms mocks base method.
+func (m *MockService) GetAlgorithms(ctx context.Context, clientID int) (*models.AlgoStatuses, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAlgorithms", ctx, clientID)
+	ret0, _ := ret[0].(*models.AlgoStatuses)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAlgorithms indicates an expected call of GetAlgorithms.
+func (mr *MockServiceMockRecorder) GetAlgorithms(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAlgorithms", reflect.TypeOf((*MockService)(nil).GetAlgorithms), ctx, clientID)
+}
+
+// GetClient mocks base method.
+func (m *MockService) GetClient(ctx context.Context, clientID int) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClient", ctx, clientID)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClient indicates an expected call of GetClient.
+func (mr *MockServiceMockRecorder) GetClient(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClient", reflect.TypeOf((*MockService)(nil).GetClient), ctx, clientID)
+}
+
+// GetParams mocks base method.
+func (m *MockService) GetParams(ctx context.Context, clientID int, algorithm string) (*models.AlgoParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetParams", ctx, clientID, algorithm)
+	ret0, _ := ret[0].(*models.AlgoParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetParams indicates an expected call of GetParams.
+func (mr *MockServiceMockRecorder) GetParams(ctx, clientID, algorithm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParams", reflect.TypeOf((*MockService)(nil).GetParams), ctx, clientID, algorithm)
+}
+
+// ListClients mocks base method.
+func (m *MockService) ListClients(ctx context.Context, query *models.ClientQuery) (*models.ClientPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClients", ctx, query)
+	ret0, _ := ret[0].(*models.ClientPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClients indicates an expected call of ListClients.
+func (mr *MockServiceMockRecorder) ListClients(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClients", reflect.TypeOf((*MockService)(nil).ListClients), ctx, query)
+}
+
+// PatchClient mocks base method.
+func (m *MockService) PatchClient(ctx context.Context, patch *models.ClientPatch) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchClient", ctx, patch)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchClient indicates an expected call of PatchClient.
+func (mr *MockServiceMockRecorder) PatchClient(ctx, patch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchClient", reflect.TypeOf((*MockService)(nil).PatchClient), ctx, patch)
+}
+
 // UpdateClient mocks base method.
-func (m *MockService) UpdateClient(ctx context.Context, clientInfo *models.Client) error {
+func (m *MockService) UpdateClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateClient", ctx, clientInfo)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdateClient indicates an expected call of UpdateClient.
@@ -77,3 +169,18 @@ func (mr *MockServiceMockRecorder) UpdateClient(ctx, clientInfo interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClient", reflect.TypeOf((*MockService)(nil).UpdateClient), ctx, clientInfo)
 }
+
+// UpdateParams mocks base method.
+func (m *MockService) UpdateParams(ctx context.Context, algoParams *models.AlgoParams) (*models.AlgoParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateParams", ctx, algoParams)
+	ret0, _ := ret[0].(*models.AlgoParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateParams indicates an expected call of UpdateParams.
+func (mr *MockServiceMockRecorder) UpdateParams(ctx, algoParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateParams", reflect.TypeOf((*MockService)(nil).UpdateParams), ctx, algoParams)
+}
@@ -2,88 +2,267 @@ package deployer
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 
 	"sync-algo/internal/config"
+	"sync-algo/internal/models"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-type Deployer struct {
-	clientset *kubernetes.Clientset
+const (
+	// managedByLabel marks the objects created by the service
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedBy      = "sync-algo"
+	// workloadLabel carries the name of the workload the object belongs to
+	workloadLabel = "sync-algo/workload"
+	// clientIDLabel carries the ID of the client the workload belongs to
+	clientIDLabel = "sync-algo/client-id"
+	// algorithmLabel carries the algorithm run by the workload
+	algorithmLabel = "sync-algo/algorithm"
+	// versionLabel carries the client's version the workload was created for
+	versionLabel = "sync-algo/version"
+	// imageAnnotation carries the client's image the workload was created for
+	imageAnnotation = "sync-algo/image"
+	// specHashAnnotation identifies the pod spec the workload was created with
+	specHashAnnotation = "sync-algo/spec-hash"
+)
+
+// ErrUnmanaged is returned when an object named as the workload exists, but wasn't created by the service,
+// so the service neither replaces nor deletes it
+var ErrUnmanaged = errors.New("object exists and is not managed by the service")
+
+// base holds the state shared by the deployers of all modes
+type base struct {
+	clientset kubernetes.Interface
 	cfg       *config.Kubernates
 }
 
-func New(cfg *config.Kubernates) (*Deployer, error) {
-	config, err := clientcmd.BuildConfigFromFlags("", cfg.KubeConfig)
+func newBase(cfg *config.Kubernates) (base, error) {
+	config, err := restConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Kubernetes config: %w", err)
+		return base{}, fmt.Errorf("failed to create Kubernetes config: %w", err)
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Kubernetes clientset: %w", err)
+		return base{}, fmt.Errorf("failed to create Kubernetes clientset: %w", err)
 	}
 
-	return &Deployer{
+	return base{
 		clientset: clientset,
+		cfg:       cfg,
 	}, nil
 }
 
-func (d *Deployer) CreatePod(name string) error {
-	pod := &v1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+// restConfig loads the kubeconfig's context if either the kubeconfig or the context is set,
+// otherwise the credentials of the pod's service account. Without the explicit kubeconfig
+// the default one is loaded, e.g. ~/.kube/config, and the context must exist in it.
+func restConfig(cfg *config.Kubernates) (*rest.Config, error) {
+	if cfg.KubeConfig == "" && cfg.KubeContext == "" {
+		return rest.InClusterConfig()
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = cfg.KubeConfig
+
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: cfg.KubeContext},
+	)
+
+	if cfg.KubeContext != "" {
+		raw, err := clientConfig.RawConfig()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := raw.Contexts[cfg.KubeContext]; !ok {
+			return nil, fmt.Errorf("context %q not found in kubeconfig", cfg.KubeContext)
+		}
+	}
+
+	return clientConfig.ClientConfig()
+}
+
+// withTimeout bounds a single request to the Kubernetes API by the configured timeout
+func (b *base) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.cfg.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, b.cfg.Timeout)
+}
+
+// objectMeta builds metadata of the objects created for the workload
+func (b *base) objectMeta(workload models.Workload) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      workload.Name,
+		Namespace: b.namespace(workload.ClientID),
+		Labels: map[string]string{
+			managedByLabel: managedBy,
+			workloadLabel:  workload.Name,
+			clientIDLabel:  strconv.Itoa(workload.ClientID),
+			algorithmLabel: workload.Algorithm,
+			versionLabel:   strconv.Itoa(workload.Version),
 		},
-		Spec: v1.PodSpec{
-			Containers: []v1.Container{
-				{
-					Name:  name,
-					Image: d.cfg.ConteinerName,
-				},
-			},
+		Annotations: map[string]string{
+			imageAnnotation:    workload.Image,
+			specHashAnnotation: b.SpecHash(workload),
 		},
 	}
+}
+
+// Image returns the image the workload runs, the configured default one if the workload has none
+func (b *base) Image(workload models.Workload) string {
+	if workload.Image == "" {
+		return b.cfg.ConteinerName
+	}
+
+	return workload.Image
+}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// SpecHash identifies the pod spec built for the workload: its image, parameters, resources and priority class.
+// The hash is empty if the spec can't be built, deploying the workload reports the error then.
+func (b *base) SpecHash(workload models.Workload) string {
+	spec, err := b.podSpec(workload)
+	if err != nil {
+		return ""
+	}
 
-	_, err := d.clientset.CoreV1().Pods("default").Create(ctx, pod, metav1.CreateOptions{})
+	raw, err := json.Marshal(spec)
 	if err != nil {
-		return fmt.Errorf("failed to create pod: %w", err)
+		return ""
 	}
 
-	return nil
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8])
 }
 
-func (d *Deployer) DeletePod(name string) error {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	err := d.clientset.CoreV1().Pods("default").Delete(ctx, name, metav1.DeleteOptions{})
+// podSpec builds specification of the pod running the workload
+func (b *base) podSpec(workload models.Workload) (v1.PodSpec, error) {
+	resources, err := resourceRequirements(workload.CPU, workload.Memory)
 	if err != nil {
-		return fmt.Errorf("failed to delete pod: %w", err)
+		return v1.PodSpec{}, fmt.Errorf("failed to build pod resources: %w", err)
 	}
 
-	return nil
+	params := workload.Params
+	if params == "" {
+		params = "{}"
+	}
+
+	return v1.PodSpec{
+		PriorityClassName: b.priorityClassName(workload.Priority),
+		Containers: []v1.Container{
+			{
+				Name:  workload.Name,
+				Image: b.Image(workload),
+				Env: []v1.EnvVar{
+					{Name: "CLIENT_ID", Value: strconv.Itoa(workload.ClientID)},
+					{Name: "ALGORITHM", Value: workload.Algorithm},
+					{Name: "ALGORITHM_PARAMS", Value: params},
+				},
+				Resources: resources,
+			},
+		},
+	}, nil
 }
 
-func (d *Deployer) GetPodList() ([]string, error) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// mergeOwned sets the service's own keys in the object's labels or annotations, keeping the others
+func mergeOwned(current, owned map[string]string) map[string]string {
+	if current == nil {
+		current = make(map[string]string, len(owned))
+	}
+	for key, value := range owned {
+		current[key] = value
+	}
 
-	pods, err := d.clientset.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to list pods: %w", err)
+	return current
+}
+
+// listOptions selects the objects created by the service
+func listOptions() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: managedByLabel + "=" + managedBy,
+	}
+}
+
+// isManaged reports whether the object was created by the service
+func isManaged(meta metav1.ObjectMeta) bool {
+	return meta.Labels[managedByLabel] == managedBy
+}
+
+// instance describes the object created for a workload
+func instance(meta metav1.ObjectMeta, phase string) models.Instance {
+	clientID, _ := strconv.Atoi(meta.Labels[clientIDLabel])
+	version, _ := strconv.Atoi(meta.Labels[versionLabel])
+
+	return models.Instance{
+		Name:      meta.Name,
+		Namespace: meta.Namespace,
+		ClientID:  clientID,
+		Algorithm: meta.Labels[algorithmLabel],
+		Phase:     phase,
+		Version:   version,
+		Image:     meta.Annotations[imageAnnotation],
+		SpecHash:  meta.Annotations[specHashAnnotation],
+	}
+}
+
+// resourceRequirements builds container's requests and limits from CPU and memory quantities.
+// Empty values leave the corresponding resource unbounded.
+func resourceRequirements(cpu, memory string) (v1.ResourceRequirements, error) {
+	list := v1.ResourceList{}
+
+	if cpu != "" {
+		quantity, err := resource.ParseQuantity(cpu)
+		if err != nil {
+			return v1.ResourceRequirements{}, fmt.Errorf("invalid cpu %q: %w", cpu, err)
+		}
+		list[v1.ResourceCPU] = quantity
+	}
+
+	if memory != "" {
+		quantity, err := resource.ParseQuantity(memory)
+		if err != nil {
+			return v1.ResourceRequirements{}, fmt.Errorf("invalid memory %q: %w", memory, err)
+		}
+		list[v1.ResourceMemory] = quantity
+	}
+
+	if len(list) == 0 {
+		return v1.ResourceRequirements{}, nil
+	}
+
+	return v1.ResourceRequirements{
+		Requests: list,
+		Limits:   list.DeepCopy(),
+	}, nil
+}
+
+// priorityClassName maps the client's priority from [0, 1] onto the configured PriorityClasses
+func (b *base) priorityClassName(priority float64) string {
+	classes := b.cfg.PriorityClasses
+	if len(classes) == 0 {
+		return ""
 	}
 
-	var podNames []string
-	for _, pod := range pods.Items {
-		podNames = append(podNames, pod.Name)
+	i := int(priority * float64(len(classes)))
+	switch {
+	case i < 0:
+		i = 0
+	case i >= len(classes):
+		i = len(classes) - 1
 	}
 
-	return podNames, nil
+	return classes[i]
 }
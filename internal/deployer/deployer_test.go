@@ -0,0 +1,433 @@
+package deployer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"sync-algo/internal/config"
+	"sync-algo/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestBase(cfg config.Kubernates, objects ...runtime.Object) base {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "algo"
+	}
+	if cfg.ConteinerName == "" {
+		cfg.ConteinerName = "default-image"
+	}
+
+	return base{
+		clientset: fake.NewSimpleClientset(objects...),
+		cfg:       &cfg,
+	}
+}
+
+func TestRestConfig(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster: {server: "https://dev.example.com"}
+- name: prod
+  cluster: {server: "https://prod.example.com"}
+users:
+- name: admin
+  user: {token: secret}
+contexts:
+- name: dev
+  context: {cluster: dev, user: admin}
+- name: prod
+  context: {cluster: prod, user: admin}
+current-context: dev
+`), 0o600))
+
+	tt := []struct {
+		name          string
+		cfg           config.Kubernates
+		expectedHost  string
+		expectedError bool
+	}{
+		{name: "Current context", cfg: config.Kubernates{KubeConfig: kubeconfig}, expectedHost: "https://dev.example.com"},
+		{name: "Selected context", cfg: config.Kubernates{KubeConfig: kubeconfig, KubeContext: "prod"}, expectedHost: "https://prod.example.com"},
+		{name: "Unknown context", cfg: config.Kubernates{KubeConfig: kubeconfig, KubeContext: "staging"}, expectedError: true},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			restConfig, err := restConfig(&tc.cfg)
+
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedHost, restConfig.Host)
+		})
+	}
+}
+
+func TestBase_podSpec(t *testing.T) {
+	tt := []struct {
+		name              string
+		cfg               config.Kubernates
+		workload          models.Workload
+		expectedImage     string
+		expectedResources v1.ResourceRequirements
+		expectedPriority  string
+		expectedParams    string
+		expectedErr       bool
+	}{
+		{
+			name:           "Default image without resources",
+			workload:       models.Workload{Name: "vwap-1", ClientID: 1, Algorithm: "vwap"},
+			expectedImage:  "default-image",
+			expectedParams: "{}",
+		},
+		{
+			name: "Client's image, resources and priority",
+			cfg:  config.Kubernates{PriorityClasses: []string{"low", "normal", "high"}},
+			workload: models.Workload{
+				Name: "vwap-1", ClientID: 1, Algorithm: "vwap",
+				Image: "client-image", CPU: "500m", Memory: "256Mi", Priority: 0.9,
+				Params: `{"window": 5}`,
+			},
+			expectedImage: "client-image",
+			expectedResources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+				Limits:   v1.ResourceList{v1.ResourceCPU: resource.MustParse("500m"), v1.ResourceMemory: resource.MustParse("256Mi")},
+			},
+			expectedPriority: "high",
+			expectedParams:   `{"window": 5}`,
+		},
+		{
+			name:        "Invalid cpu",
+			workload:    models.Workload{Name: "vwap-1", ClientID: 1, Algorithm: "vwap", CPU: "lots"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestBase(tc.cfg)
+
+			spec, err := b.podSpec(tc.workload)
+			if tc.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, spec.Containers, 1)
+
+			container := spec.Containers[0]
+			assert.Equal(t, tc.expectedImage, container.Image)
+			assert.Equal(t, tc.expectedResources, container.Resources)
+			assert.Equal(t, tc.expectedPriority, spec.PriorityClassName)
+			assert.Contains(t, container.Env, v1.EnvVar{Name: "ALGORITHM_PARAMS", Value: tc.expectedParams})
+			assert.Contains(t, container.Env, v1.EnvVar{Name: "CLIENT_ID", Value: "1"})
+		})
+	}
+}
+
+func TestBase_priorityClassName(t *testing.T) {
+	tt := []struct {
+		name     string
+		classes  []string
+		priority float64
+		expected string
+	}{
+		{name: "No classes", priority: 0.5, expected: ""},
+		{name: "Lowest priority", classes: []string{"low", "high"}, priority: 0, expected: "low"},
+		{name: "Highest priority", classes: []string{"low", "high"}, priority: 1, expected: "high"},
+		{name: "Below range", classes: []string{"low", "high"}, priority: -1, expected: "low"},
+		{name: "Above range", classes: []string{"low", "high"}, priority: 2, expected: "high"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestBase(config.Kubernates{PriorityClasses: tc.classes})
+
+			assert.Equal(t, tc.expected, b.priorityClassName(tc.priority))
+		})
+	}
+}
+
+func TestBase_objectMeta(t *testing.T) {
+	tt := []struct {
+		name              string
+		cfg               config.Kubernates
+		expectedNamespace string
+	}{
+		{
+			name:              "Shared namespace",
+			expectedNamespace: "algo",
+		},
+		{
+			name:              "Namespace per client",
+			cfg:               config.Kubernates{NamespacePerClient: true},
+			expectedNamespace: "algo-client-7",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestBase(tc.cfg)
+
+			workload := models.Workload{
+				Name: "twap-7", ClientID: 7, Algorithm: "twap", Version: 3, Image: "client-image", Params: `{"a": 1}`,
+			}
+			meta := b.objectMeta(workload)
+
+			assert.Equal(t, "twap-7", meta.Name)
+			assert.Equal(t, tc.expectedNamespace, meta.Namespace)
+			assert.Equal(t, map[string]string{
+				managedByLabel: managedBy,
+				workloadLabel:  "twap-7",
+				clientIDLabel:  "7",
+				algorithmLabel: "twap",
+				versionLabel:   "3",
+			}, meta.Labels)
+			assert.Equal(t, "client-image", meta.Annotations[imageAnnotation])
+			assert.Equal(t, b.SpecHash(workload), meta.Annotations[specHashAnnotation])
+			assert.True(t, b.isManagedNamespace(meta.Namespace))
+		})
+	}
+}
+
+func TestBase_SpecHash(t *testing.T) {
+	b := newTestBase(config.Kubernates{PriorityClasses: []string{"low", "high"}})
+
+	workload := models.Workload{
+		Name: "twap-7", ClientID: 7, Algorithm: "twap", Version: 3, Image: "client-image",
+		CPU: "500m", Memory: "512Mi", Priority: 0.25, Params: `{"a": 1}`,
+	}
+	hash := b.SpecHash(workload)
+	require.NotEmpty(t, hash)
+
+	tt := []struct {
+		name         string
+		change       func(w *models.Workload)
+		expectedSame bool
+	}{
+		{name: "Same spec", change: func(w *models.Workload) {}, expectedSame: true},
+		{name: "Version is compared on its own", change: func(w *models.Workload) { w.Version = 4 }, expectedSame: true},
+		{name: "Priority of the same class", change: func(w *models.Workload) { w.Priority = 0.4 }, expectedSame: true},
+		{name: "Image", change: func(w *models.Workload) { w.Image = "other-image" }},
+		{name: "CPU", change: func(w *models.Workload) { w.CPU = "1" }},
+		{name: "Memory", change: func(w *models.Workload) { w.Memory = "1Gi" }},
+		{name: "Priority class", change: func(w *models.Workload) { w.Priority = 0.75 }},
+		{name: "Parameters", change: func(w *models.Workload) { w.Params = `{"a": 2}` }},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			changed := workload
+			tc.change(&changed)
+
+			if tc.expectedSame {
+				assert.Equal(t, hash, b.SpecHash(changed))
+			} else {
+				assert.NotEqual(t, hash, b.SpecHash(changed))
+			}
+		})
+	}
+}
+
+func TestPodDeployer_List(t *testing.T) {
+	managed := map[string]string{managedByLabel: managedBy, clientIDLabel: "1", algorithmLabel: "vwap", versionLabel: "2"}
+
+	tt := []struct {
+		name          string
+		cfg           config.Kubernates
+		objects       []runtime.Object
+		expectedNames []string
+	}{
+		{
+			name: "Only managed pods of the namespace",
+			objects: []runtime.Object{
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "vwap-1", Namespace: "algo", Labels: managed}},
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foreign", Namespace: "algo"}},
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "vwap-1", Namespace: "other", Labels: managed}},
+			},
+			expectedNames: []string{"vwap-1"},
+		},
+		{
+			name: "Managed pods of the clients' namespaces",
+			cfg:  config.Kubernates{NamespacePerClient: true},
+			objects: []runtime.Object{
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "vwap-1", Namespace: "algo-client-1", Labels: managed}},
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foreign", Namespace: "algo-client-1"}},
+				&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "vwap-1", Namespace: "other", Labels: managed}},
+			},
+			expectedNames: []string{"vwap-1"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &PodDeployer{base: newTestBase(tc.cfg, tc.objects...)}
+
+			instances, err := d.List(context.Background())
+			require.NoError(t, err)
+
+			var names []string
+			for _, instance := range instances {
+				names = append(names, instance.Name)
+				assert.Equal(t, 1, instance.ClientID)
+				assert.Equal(t, "vwap", instance.Algorithm)
+				assert.Equal(t, 2, instance.Version)
+			}
+			assert.Equal(t, tc.expectedNames, names)
+		})
+	}
+}
+
+func TestPodDeployer_Create(t *testing.T) {
+	d := &PodDeployer{base: newTestBase(config.Kubernates{NamespacePerClient: true})}
+
+	err := d.Create(context.Background(), models.Workload{Name: "hft-3", ClientID: 3, Algorithm: "hft"})
+	require.NoError(t, err)
+
+	_, err = d.clientset.CoreV1().Namespaces().Get(context.Background(), "algo-client-3", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	pod, err := d.clientset.CoreV1().Pods("algo-client-3").Get(context.Background(), "hft-3", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, managedBy, pod.Labels[managedByLabel])
+}
+
+func TestDeploymentDeployer_Update(t *testing.T) {
+	d := &DeploymentDeployer{base: newTestBase(config.Kubernates{})}
+	workload := models.Workload{Name: "vwap-1", ClientID: 1, Algorithm: "vwap", Version: 1}
+
+	require.NoError(t, d.Create(context.Background(), workload))
+
+	deployments := d.clientset.AppsV1().Deployments("algo")
+
+	// Roll the pods back in time to see the update restarting them
+	created, err := deployments.Get(context.Background(), "vwap-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	created.Spec.Template.Annotations[restartedAtAnnotation] = time.Unix(0, 0).Format(time.RFC3339)
+	// Metadata set by Kubernetes and other controllers
+	created.Annotations["deployment.kubernetes.io/revision"] = "1"
+	created.Labels["team"] = "trading"
+	_, err = deployments.Update(context.Background(), created, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	workload.Version = 2
+	require.NoError(t, d.Update(context.Background(), workload))
+
+	updated, err := deployments.Get(context.Background(), "vwap-1", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "2", updated.Labels[versionLabel])
+	assert.Equal(t, "trading", updated.Labels["team"])
+	assert.Equal(t, "1", updated.Annotations["deployment.kubernetes.io/revision"])
+	assert.Equal(t, d.SpecHash(workload), updated.Annotations[specHashAnnotation])
+	assert.Equal(t, "2", updated.Spec.Template.Labels[versionLabel])
+	assert.NotEqual(t, time.Unix(0, 0).Format(time.RFC3339), updated.Spec.Template.Annotations[restartedAtAnnotation])
+	assert.Equal(t, int32(1), *updated.Spec.Replicas)
+}
+
+func TestDeploymentPhase(t *testing.T) {
+	assert.Equal(t, string(v1.PodPending), deploymentPhase(appsv1.Deployment{}))
+	assert.Equal(t, string(v1.PodRunning), deploymentPhase(appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{AvailableReplicas: 1},
+	}))
+}
+
+func TestPodDeployer_Create_existing(t *testing.T) {
+	tt := []struct {
+		name          string
+		labels        map[string]string
+		expectedErr   error
+		expectedImage string
+	}{
+		{
+			name:          "Adopt managed pod",
+			labels:        map[string]string{managedByLabel: managedBy},
+			expectedImage: "default-image",
+		},
+		{
+			name:          "Refuse foreign pod",
+			labels:        map[string]string{"app": "foreign"},
+			expectedErr:   ErrUnmanaged,
+			expectedImage: "foreign-image",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			existing := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "vwap-1", Namespace: "algo", Labels: tc.labels},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "vwap-1", Image: "foreign-image"}}},
+			}
+			d := &PodDeployer{base: newTestBase(config.Kubernates{}, existing)}
+
+			err := d.Create(context.Background(), models.Workload{Name: "vwap-1", ClientID: 1, Algorithm: "vwap"})
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			pod, err := d.clientset.CoreV1().Pods("algo").Get(context.Background(), "vwap-1", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedImage, pod.Spec.Containers[0].Image)
+		})
+	}
+}
+
+func TestDeploymentDeployer_Create_existing(t *testing.T) {
+	tt := []struct {
+		name            string
+		labels          map[string]string
+		expectedErr     error
+		expectedVersion string
+	}{
+		{
+			name:            "Adopt managed deployment",
+			labels:          map[string]string{managedByLabel: managedBy, versionLabel: "1"},
+			expectedVersion: "2",
+		},
+		{
+			name:            "Refuse foreign deployment",
+			labels:          map[string]string{versionLabel: "1"},
+			expectedErr:     ErrUnmanaged,
+			expectedVersion: "1",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			existing := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "vwap-1", Namespace: "algo", Labels: tc.labels},
+			}
+			d := &DeploymentDeployer{base: newTestBase(config.Kubernates{}, existing)}
+
+			err := d.Create(context.Background(), models.Workload{Name: "vwap-1", ClientID: 1, Algorithm: "vwap", Version: 2})
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			deployment, err := d.clientset.AppsV1().Deployments("algo").Get(context.Background(), "vwap-1", metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedVersion, deployment.Labels[versionLabel])
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sync-algo/internal/config"
+	"sync-algo/internal/models"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// restartedAtAnnotation changes on every update to roll the Deployment's pods even if the spec is the same
+const restartedAtAnnotation = "sync-algo/restarted-at"
+
+// DeploymentDeployer runs every workload as a Deployment with a single replica,
+// so Kubernetes reschedules it after node failure or eviction
+type DeploymentDeployer struct {
+	base
+}
+
+func NewDeploymentDeployer(cfg *config.Kubernates) (*DeploymentDeployer, error) {
+	b, err := newBase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DeploymentDeployer{base: b}, nil
+}
+
+func (d *DeploymentDeployer) Create(ctx context.Context, workload models.Workload) error {
+	err := d.create(ctx, workload)
+	if apierrors.IsAlreadyExists(err) {
+		// Adopt the service's own Deployment missed by the listing, the foreign ones are refused by Update
+		return d.Update(ctx, workload)
+	}
+
+	return err
+}
+
+func (d *DeploymentDeployer) create(ctx context.Context, workload models.Workload) error {
+	deployment, err := d.deployment(workload)
+	if err != nil {
+		return err
+	}
+
+	if err := d.ensureNamespace(ctx, workload.ClientID); err != nil {
+		return err
+	}
+
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	_, err = d.clientset.AppsV1().Deployments(deployment.Namespace).Create(ctx, deployment, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create deployment: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces the Deployment's pod template, rolling its pods.
+// A missing Deployment is created, the one of the same name not created by the service
+// is left as it is and ErrUnmanaged is returned.
+func (d *DeploymentDeployer) Update(ctx context.Context, workload models.Workload) error {
+	desired, err := d.deployment(workload)
+	if err != nil {
+		return err
+	}
+
+	deployments := d.clientset.AppsV1().Deployments(desired.Namespace)
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ctx, cancel := d.withTimeout(ctx)
+		defer cancel()
+
+		deployment, err := deployments.Get(ctx, workload.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if !isManaged(deployment.ObjectMeta) {
+			return fmt.Errorf("deployment %s/%s: %w", desired.Namespace, workload.Name, ErrUnmanaged)
+		}
+
+		// The labels and annotations of Kubernetes and other controllers are kept
+		deployment.Labels = mergeOwned(deployment.Labels, desired.Labels)
+		deployment.Annotations = mergeOwned(deployment.Annotations, desired.Annotations)
+		deployment.Spec.Template = desired.Spec.Template
+
+		_, err = deployments.Update(ctx, deployment, metav1.UpdateOptions{})
+		return err
+	})
+	if apierrors.IsNotFound(err) {
+		return d.create(ctx, workload)
+	}
+	if errors.Is(err, ErrUnmanaged) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update deployment: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DeploymentDeployer) Delete(ctx context.Context, instance models.Instance) error {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	err := d.clientset.AppsV1().Deployments(instance.Namespace).Delete(ctx, instance.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete deployment: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DeploymentDeployer) List(ctx context.Context) ([]models.Instance, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	deployments, err := d.clientset.AppsV1().Deployments(d.listNamespace()).List(ctx, listOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+
+	var instances []models.Instance
+	for _, deployment := range deployments.Items {
+		if d.isManagedNamespace(deployment.Namespace) {
+			instances = append(instances, instance(deployment.ObjectMeta, deploymentPhase(deployment)))
+		}
+	}
+
+	return instances, nil
+}
+
+// deployment builds the Deployment running the workload
+func (d *DeploymentDeployer) deployment(workload models.Workload) (*appsv1.Deployment, error) {
+	spec, err := d.podSpec(workload)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := d.objectMeta(workload)
+
+	template := d.objectMeta(workload)
+	template.Name = ""
+	template.Namespace = ""
+	template.Annotations[restartedAtAnnotation] = time.Now().Format(time.RFC3339)
+
+	replicas := int32(1)
+
+	return &appsv1.Deployment{
+		ObjectMeta: meta,
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					workloadLabel: workload.Name,
+				},
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: template,
+				Spec:       spec,
+			},
+		},
+	}, nil
+}
+
+// deploymentPhase reports the Deployment's state in terms of its pod's phase
+func deploymentPhase(deployment appsv1.Deployment) string {
+	if deployment.Status.AvailableReplicas > 0 {
+		return string(v1.PodRunning)
+	}
+
+	return string(v1.PodPending)
+}
@@ -0,0 +1,316 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scheduler.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+	models "sync-algo/internal/models"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockDeployer is a mock of Deployer interface.
+type MockDeployer struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeployerMockRecorder
+}
+
+// MockDeployerMockRecorder is the mock recorder for MockDeployer.
+type MockDeployerMockRecorder struct {
+	mock *MockDeployer
+}
+
+// NewMockDeployer creates a new mock instance.
+func NewMockDeployer(ctrl *gomock.Controller) *MockDeployer {
+	mock := &MockDeployer{ctrl: ctrl}
+	mock.recorder = &MockDeployerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeployer) EXPECT() *MockDeployerMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockDeployer) Create(ctx context.Context, workload models.Workload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, workload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDeployerMockRecorder) Create(ctx, workload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDeployer)(nil).Create), ctx, workload)
+}
+
+// Delete mocks base method.
+func (m *MockDeployer) Delete(ctx context.Context, instance models.Instance) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, instance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockDeployerMockRecorder) Delete(ctx, instance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDeployer)(nil).Delete), ctx, instance)
+}
+
+// Image mocks base method.
+func (m *MockDeployer) Image(workload models.Workload) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Image", workload)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Image indicates an expected call of Image.
+func (mr *MockDeployerMockRecorder) Image(workload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Image", reflect.TypeOf((*MockDeployer)(nil).Image), workload)
+}
+
+// List mocks base method.
+func (m *MockDeployer) List(ctx context.Context) ([]models.Instance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]models.Instance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockDeployerMockRecorder) List(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDeployer)(nil).List), ctx)
+}
+
+// SpecHash mocks base method.
+func (m *MockDeployer) SpecHash(workload models.Workload) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpecHash", workload)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// SpecHash indicates an expected call of SpecHash.
+func (mr *MockDeployerMockRecorder) SpecHash(workload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpecHash", reflect.TypeOf((*MockDeployer)(nil).SpecHash), workload)
+}
+
+// Update mocks base method.
+func (m *MockDeployer) Update(ctx context.Context, workload models.Workload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, workload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDeployerMockRecorder) Update(ctx, workload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDeployer)(nil).Update), ctx, workload)
+}
+
+// Watch mocks base method.
+func (m *MockDeployer) Watch(ctx context.Context, onChange func(models.Instance)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Watch", ctx, onChange)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch.
+func (mr *MockDeployerMockRecorder) Watch(ctx, onChange interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockDeployer)(nil).Watch), ctx, onChange)
+}
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// CompleteRestart mocks base method.
+func (m *MockStorage) CompleteRestart(ctx context.Context, clientID, revision int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteRestart", ctx, clientID, revision)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteRestart indicates an expected call of CompleteRestart.
+func (mr *MockStorageMockRecorder) CompleteRestart(ctx, clientID, revision interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteRestart", reflect.TypeOf((*MockStorage)(nil).CompleteRestart), ctx, clientID, revision)
+}
+
+// DeleteWorkload mocks base method.
+func (m *MockStorage) DeleteWorkload(ctx context.Context, clientID int, algorithm string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkload", ctx, clientID, algorithm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkload indicates an expected call of DeleteWorkload.
+func (mr *MockStorageMockRecorder) DeleteWorkload(ctx, clientID, algorithm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkload", reflect.TypeOf((*MockStorage)(nil).DeleteWorkload), ctx, clientID, algorithm)
+}
+
+// FailRestart mocks base method.
+func (m *MockStorage) FailRestart(ctx context.Context, clientID int, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailRestart", ctx, clientID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailRestart indicates an expected call of FailRestart.
+func (mr *MockStorageMockRecorder) FailRestart(ctx, clientID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailRestart", reflect.TypeOf((*MockStorage)(nil).FailRestart), ctx, clientID, reason)
+}
+
+// FailWorkload mocks base method.
+func (m *MockStorage) FailWorkload(ctx context.Context, workload models.Workload, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailWorkload", ctx, workload, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FailWorkload indicates an expected call of FailWorkload.
+func (mr *MockStorageMockRecorder) FailWorkload(ctx, workload, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailWorkload", reflect.TypeOf((*MockStorage)(nil).FailWorkload), ctx, workload, reason)
+}
+
+// FetchAlgorithms mocks base method.
+func (m *MockStorage) FetchAlgorithms(ctx context.Context) ([]models.Algorithm, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAlgorithms", ctx)
+	ret0, _ := ret[0].([]models.Algorithm)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAlgorithms indicates an expected call of FetchAlgorithms.
+func (mr *MockStorageMockRecorder) FetchAlgorithms(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAlgorithms", reflect.TypeOf((*MockStorage)(nil).FetchAlgorithms), ctx)
+}
+
+// FetchClients mocks base method.
+func (m *MockStorage) FetchClients(ctx context.Context) ([]models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchClients", ctx)
+	ret0, _ := ret[0].([]models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchClients indicates an expected call of FetchClients.
+func (mr *MockStorageMockRecorder) FetchClients(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchClients", reflect.TypeOf((*MockStorage)(nil).FetchClients), ctx)
+}
+
+// FetchCurrentParams mocks base method.
+func (m *MockStorage) FetchCurrentParams(ctx context.Context) ([]models.AlgoParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchCurrentParams", ctx)
+	ret0, _ := ret[0].([]models.AlgoParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchCurrentParams indicates an expected call of FetchCurrentParams.
+func (mr *MockStorageMockRecorder) FetchCurrentParams(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchCurrentParams", reflect.TypeOf((*MockStorage)(nil).FetchCurrentParams), ctx)
+}
+
+// FetchCurrentStatuses mocks base method.
+func (m *MockStorage) FetchCurrentStatuses(ctx context.Context) ([]models.AlgoStatuses, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchCurrentStatuses", ctx)
+	ret0, _ := ret[0].([]models.AlgoStatuses)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchCurrentStatuses indicates an expected call of FetchCurrentStatuses.
+func (mr *MockStorageMockRecorder) FetchCurrentStatuses(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchCurrentStatuses", reflect.TypeOf((*MockStorage)(nil).FetchCurrentStatuses), ctx)
+}
+
+// Listen mocks base method.
+func (m *MockStorage) Listen(ctx context.Context, notify chan<- struct{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Listen", ctx, notify)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Listen indicates an expected call of Listen.
+func (mr *MockStorageMockRecorder) Listen(ctx, notify interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Listen", reflect.TypeOf((*MockStorage)(nil).Listen), ctx, notify)
+}
+
+// SaveWorkload mocks base method.
+func (m *MockStorage) SaveWorkload(ctx context.Context, workload models.Workload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWorkload", ctx, workload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWorkload indicates an expected call of SaveWorkload.
+func (mr *MockStorageMockRecorder) SaveWorkload(ctx, workload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWorkload", reflect.TypeOf((*MockStorage)(nil).SaveWorkload), ctx, workload)
+}
+
+// UpdateWorkloadPhase mocks base method.
+func (m *MockStorage) UpdateWorkloadPhase(ctx context.Context, instance models.Instance) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkloadPhase", ctx, instance)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkloadPhase indicates an expected call of UpdateWorkloadPhase.
+func (mr *MockStorageMockRecorder) UpdateWorkloadPhase(ctx, instance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkloadPhase", reflect.TypeOf((*MockStorage)(nil).UpdateWorkloadPhase), ctx, instance)
+}
@@ -0,0 +1,91 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreateNamespace creates the client's namespace, if every client gets its own one
+func (b *base) CreateNamespace(ctx context.Context, clientID int) error {
+	if !b.cfg.NamespacePerClient {
+		return nil
+	}
+
+	return b.ensureNamespace(ctx, clientID)
+}
+
+// DeleteNamespace deletes the client's namespace together with all of its workloads,
+// if every client gets its own one
+func (b *base) DeleteNamespace(ctx context.Context, clientID int) error {
+	if !b.cfg.NamespacePerClient {
+		return nil
+	}
+
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	err := b.clientset.CoreV1().Namespaces().Delete(ctx, b.namespace(clientID), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete namespace: %w", err)
+	}
+
+	return nil
+}
+
+// ensureNamespace creates the client's namespace unless it exists
+func (b *base) ensureNamespace(ctx context.Context, clientID int) error {
+	if !b.cfg.NamespacePerClient {
+		return nil
+	}
+
+	namespace := &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: b.namespace(clientID),
+			Labels: map[string]string{
+				managedByLabel: managedBy,
+			},
+		},
+	}
+
+	ctx, cancel := b.withTimeout(ctx)
+	defer cancel()
+
+	_, err := b.clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace: %w", err)
+	}
+
+	return nil
+}
+
+// namespace returns the namespace of the client's workloads
+func (b *base) namespace(clientID int) string {
+	if !b.cfg.NamespacePerClient {
+		return b.cfg.Namespace
+	}
+
+	return fmt.Sprintf("%s-client-%d", b.cfg.Namespace, clientID)
+}
+
+// listNamespace returns the namespace to list workloads in
+func (b *base) listNamespace() string {
+	if !b.cfg.NamespacePerClient {
+		return b.cfg.Namespace
+	}
+
+	return metav1.NamespaceAll
+}
+
+// isManagedNamespace reports whether the namespace holds the service's workloads
+func (b *base) isManagedNamespace(namespace string) bool {
+	if !b.cfg.NamespacePerClient {
+		return namespace == b.cfg.Namespace
+	}
+
+	return strings.HasPrefix(namespace, b.cfg.Namespace+"-client-")
+}
@@ -0,0 +1,157 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sync-algo/internal/config"
+	"sync-algo/internal/models"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	terminationTimeout      = 2 * time.Minute
+	terminationPollInterval = 2 * time.Second
+)
+
+// PodDeployer runs every workload as a bare Pod
+type PodDeployer struct {
+	base
+}
+
+func NewPodDeployer(cfg *config.Kubernates) (*PodDeployer, error) {
+	b, err := newBase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PodDeployer{base: b}, nil
+}
+
+func (d *PodDeployer) Create(ctx context.Context, workload models.Workload) error {
+	err := d.create(ctx, workload)
+	if apierrors.IsAlreadyExists(err) {
+		// Adopt the service's own pod missed by the listing, the foreign ones are refused by Update
+		return d.Update(ctx, workload)
+	}
+
+	return err
+}
+
+func (d *PodDeployer) create(ctx context.Context, workload models.Workload) error {
+	spec, err := d.podSpec(workload)
+	if err != nil {
+		return err
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: d.objectMeta(workload),
+		Spec:       spec,
+	}
+
+	if err := d.ensureNamespace(ctx, workload.ClientID); err != nil {
+		return err
+	}
+
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	_, err = d.clientset.CoreV1().Pods(pod.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create pod: %w", err)
+	}
+
+	return nil
+}
+
+// Update recreates the pod, since most of the pod's spec is immutable.
+// The pod of the same name not created by the service is left as it is and ErrUnmanaged is returned.
+func (d *PodDeployer) Update(ctx context.Context, workload models.Workload) error {
+	namespace := d.namespace(workload.ClientID)
+
+	pod, err := d.getPod(ctx, namespace, workload.Name)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get pod: %w", err)
+	}
+	if err == nil && !isManaged(pod.ObjectMeta) {
+		return fmt.Errorf("pod %s/%s: %w", namespace, workload.Name, ErrUnmanaged)
+	}
+
+	if err := d.Delete(ctx, models.Instance{Name: workload.Name, Namespace: namespace}); err != nil {
+		return err
+	}
+
+	if err := d.waitForTermination(ctx, namespace, workload.Name); err != nil {
+		return err
+	}
+
+	return d.create(ctx, workload)
+}
+
+func (d *PodDeployer) Delete(ctx context.Context, instance models.Instance) error {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	err := d.clientset.CoreV1().Pods(instance.Namespace).Delete(ctx, instance.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete pod: %w", err)
+	}
+
+	return nil
+}
+
+func (d *PodDeployer) List(ctx context.Context) ([]models.Instance, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	pods, err := d.clientset.CoreV1().Pods(d.listNamespace()).List(ctx, listOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var instances []models.Instance
+	for _, pod := range pods.Items {
+		if d.isManagedNamespace(pod.Namespace) {
+			instances = append(instances, instance(pod.ObjectMeta, string(pod.Status.Phase)))
+		}
+	}
+
+	return instances, nil
+}
+
+// waitForTermination polls the pod until it is gone
+func (d *PodDeployer) waitForTermination(ctx context.Context, namespace, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, terminationTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(terminationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		_, err := d.getPod(ctx, namespace, name)
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get pod: %w", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("failed to wait for pod termination: %w", ctx.Err())
+		}
+	}
+}
+
+// getPod fetches the pod, the missing one is reported by the NotFound error
+func (d *PodDeployer) getPod(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+	ctx, cancel := d.withTimeout(ctx)
+	defer cancel()
+
+	return d.clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+}
@@ -0,0 +1,93 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sync-algo/internal/models"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Watch calls onChange whenever a managed pod appears, changes its phase, restarts or is deleted,
+// including out-of-band changes. It blocks until the context is cancelled.
+func (b *base) Watch(ctx context.Context, onChange func(instance models.Instance)) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(b.clientset, 0,
+		informers.WithNamespace(b.listNamespace()),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = listOptions().LabelSelector
+		}),
+	)
+	defer factory.Shutdown()
+
+	informer := factory.Core().V1().Pods().Informer()
+
+	_, err := informer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+
+			pod, ok := obj.(*v1.Pod)
+			return ok && b.isManagedNamespace(pod.Namespace)
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				onChange(podInstance(obj.(*v1.Pod)))
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				old, current := podInstance(oldObj.(*v1.Pod)), podInstance(newObj.(*v1.Pod))
+				if old.Phase != current.Phase || old.Reason != current.Reason || old.RestartCount != current.RestartCount {
+					onChange(current)
+				}
+			},
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+
+				instance := podInstance(obj.(*v1.Pod))
+				instance.Phase = models.PhaseDeleted
+				instance.Reason = ""
+				onChange(instance)
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch pods: %w", err)
+	}
+
+	factory.Start(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return errors.New("failed to sync pods cache")
+	}
+
+	<-ctx.Done()
+
+	return nil
+}
+
+// podInstance describes the pod along with the reason of its phase
+func podInstance(pod *v1.Pod) models.Instance {
+	instance := instance(pod.ObjectMeta, string(pod.Status.Phase))
+	instance.Reason = pod.Status.Reason
+
+	for _, status := range pod.Status.ContainerStatuses {
+		instance.RestartCount += int(status.RestartCount)
+	}
+
+	// Waiting container explains why the pod is not running, e.g. CrashLoopBackOff
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason != "" {
+			instance.Reason = status.State.Waiting.Reason
+			break
+		}
+	}
+
+	return instance
+}
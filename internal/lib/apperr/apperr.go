@@ -0,0 +1,68 @@
+package apperr
+
+import "errors"
+
+// Kind classifies errors by the way the API reports them
+type Kind int
+
+const (
+	// KindInternal is an unexpected failure, its details are not exposed
+	KindInternal Kind = iota
+	// KindBadRequest is a request which can't be parsed
+	KindBadRequest
+	// KindValidation is a well-formed request with invalid data
+	KindValidation
+	// KindUnauthenticated is a request without valid credentials
+	KindUnauthenticated
+	// KindForbidden is a request of the caller not allowed to perform it
+	KindForbidden
+	// KindNotFound is a request of a missing resource
+	KindNotFound
+	// KindConflict is a request conflicting with the current state of the resource
+	KindConflict
+	// KindPrecondition is a conditional request whose condition doesn't hold, e.g. a stale revision
+	KindPrecondition
+	// KindUnavailable is a failure of a dependency, e.g. the database or the cluster, worth retrying later
+	KindUnavailable
+)
+
+// Error is an error of a known kind with a machine-readable code
+type Error struct {
+	Kind Kind
+	// Code identifies the error for the API clients, e.g. client_not_found
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// New creates an error of the kind, usually a sentinel compared with errors.Is
+func New(kind Kind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// BadRequest creates an error of the request which can't be parsed
+func BadRequest(code, message string) *Error {
+	return New(KindBadRequest, code, message)
+}
+
+// Validation creates an error of the request with invalid data
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+// As returns the error of a known kind in the err's chain, an internal error if there is none
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return New(KindInternal, "internal", "internal error")
+}
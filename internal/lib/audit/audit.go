@@ -0,0 +1,29 @@
+package audit
+
+import "context"
+
+// SystemActor is the actor of the changes made outside of the API requests
+const SystemActor = "system"
+
+// Actor is who changes the state and within which request
+type Actor struct {
+	Subject   string
+	RequestID string
+}
+
+type actorKey struct{}
+
+// WithActor returns the context carrying the actor of the changes made with it
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor of the changes made with the context, SystemActor if there is none
+func ActorFrom(ctx context.Context) Actor {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	if !ok {
+		return Actor{Subject: SystemActor}
+	}
+
+	return actor
+}
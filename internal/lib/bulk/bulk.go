@@ -0,0 +1,74 @@
+package bulk
+
+import (
+	"errors"
+
+	"sync-algo/internal/lib/apperr"
+	"sync-algo/internal/lib/response"
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+)
+
+// Result is the outcome of a single item of the batch
+type Result[R any] struct {
+	Status string
+	// Value is the applied item, set only for the ok ones
+	Value *R
+	Error string
+	Code  string
+}
+
+// Apply validates the batch's items and applies the valid ones, returning whether the batch was applied
+// and the outcome of every item. An invalid item rejects the atomic batch as a whole, so none of its items
+// is tried, otherwise the invalid items are reported along with the applied ones.
+// The errors of apply other than storage.ErrBatchAborted are returned as they are.
+func Apply[T, R any](
+	items []T,
+	atomic bool,
+	validate func(item *T) *apperr.Error,
+	apply func(valid []T) ([]*R, []error, error),
+) (bool, []Result[R], error) {
+	errs := make([]error, len(items))
+	var valid []T
+	var positions []int
+	for i := range items {
+		if err := validate(&items[i]); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		valid = append(valid, items[i])
+		positions = append(positions, i)
+	}
+
+	applied := !atomic || len(valid) == len(items)
+	values := make([]*R, len(items))
+	if applied && len(valid) > 0 {
+		appliedValues, appliedErrs, err := apply(valid)
+		if err != nil && !errors.Is(err, storage.ErrBatchAborted) {
+			return false, nil, err
+		}
+		applied = err == nil
+
+		for j, i := range positions {
+			values[i], errs[i] = appliedValues[j], appliedErrs[j]
+		}
+	}
+
+	results := make([]Result[R], len(items))
+	for i := range items {
+		switch {
+		case errs[i] != nil:
+			_, resp := response.FromError(errs[i])
+			results[i] = Result[R]{Status: models.BatchItemError, Error: resp.Error, Code: resp.Code}
+		case !applied && values[i] != nil:
+			results[i] = Result[R]{Status: models.BatchItemRolledBack}
+		case !applied:
+			results[i] = Result[R]{Status: models.BatchItemSkipped}
+		default:
+			results[i] = Result[R]{Status: models.BatchItemOK, Value: values[i]}
+		}
+	}
+
+	return applied, results, nil
+}
@@ -0,0 +1,36 @@
+package queryparams
+
+import (
+	"net/url"
+	"time"
+
+	"sync-algo/internal/lib/apperr"
+)
+
+// TimeBound is the query parameter of a time filter and the bound it is read into
+type TimeBound struct {
+	Param string
+	Bound **time.Time
+}
+
+// ParseTimes reads the RFC 3339 times of the set parameters into their bounds, converted to UTC
+// as the timestamps are stored in it
+func ParseTimes(values url.Values, bounds ...TimeBound) *apperr.Error {
+	for _, b := range bounds {
+		if value := values.Get(b.Param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return InvalidFilter(b.Param)
+			}
+			parsed = parsed.UTC()
+			*b.Bound = &parsed
+		}
+	}
+
+	return nil
+}
+
+// InvalidFilter reports the query parameter which can't be parsed
+func InvalidFilter(param string) *apperr.Error {
+	return apperr.BadRequest("invalid_filter", "Invalid filter "+param)
+}
@@ -1,5 +1,15 @@
 package response
 
+import (
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+
+	"sync-algo/internal/lib/apperr"
+
+	"github.com/go-chi/render"
+)
+
 const (
 	StatusOK  = "OK"
 	StatusErr = "Error"
@@ -10,6 +20,20 @@ type Response struct {
 	Status  string `json:"status"`            // Status of the response (OK or Error)
 	Message string `json:"message,omitempty"` // Optional message for successful responses
 	Error   string `json:"error,omitempty"`   // Optional error message for error responses
+	Code    string `json:"code,omitempty"`    // Optional machine-readable code of the error, e.g. client_not_found
+}
+
+// statuses maps the kinds of the errors to HTTP status codes
+var statuses = map[apperr.Kind]int{
+	apperr.KindInternal:        http.StatusInternalServerError,
+	apperr.KindBadRequest:      http.StatusBadRequest,
+	apperr.KindValidation:      http.StatusUnprocessableEntity,
+	apperr.KindUnauthenticated: http.StatusUnauthorized,
+	apperr.KindForbidden:       http.StatusForbidden,
+	apperr.KindNotFound:        http.StatusNotFound,
+	apperr.KindConflict:        http.StatusConflict,
+	apperr.KindPrecondition:    http.StatusPreconditionFailed,
+	apperr.KindUnavailable:     http.StatusServiceUnavailable,
 }
 
 // Ok - функция для создания успешного ответа
@@ -27,3 +51,33 @@ func Err(errMsg string) Response {
 		Error:  errMsg,
 	}
 }
+
+// FromError - функция для создания ответа с ошибкой и его HTTP статуса по виду ошибки.
+// Детали ошибок неизвестного вида не раскрываются.
+func FromError(err error) (int, Response) {
+	appErr := apperr.As(err)
+
+	return statuses[appErr.Kind], Response{
+		Status: StatusErr,
+		Error:  capitalize(appErr.Message),
+		Code:   appErr.Code,
+	}
+}
+
+// Error - функция для отправки ответа с ошибкой
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	status, resp := FromError(err)
+
+	render.Status(r, status)
+	render.JSON(w, r, resp)
+}
+
+// capitalize makes the error message a sentence, as the messages of the API are
+func capitalize(msg string) string {
+	if msg == "" {
+		return msg
+	}
+
+	first, size := utf8.DecodeRuneInString(msg)
+	return string(unicode.ToUpper(first)) + msg[size:]
+}
@@ -0,0 +1,13 @@
+package models
+
+// Algorithm represents an algorithm of the catalog.
+type Algorithm struct {
+	Name string `json:"name" example:"vwap"`
+	// Image, CPU and Memory are the defaults for the clients which don't set their own.
+	Image  string `json:"image,omitempty" example:"vwap:1.4.0"`
+	CPU    string `json:"cpu,omitempty" example:"250m"`
+	Memory string `json:"memory,omitempty" example:"256Mi"`
+	// Enabled allows to run the algorithm for the clients, a disabled algorithm is stopped for everyone.
+	// Left out on save, it keeps the stored value, a new algorithm is enabled.
+	Enabled *bool `json:"enabled" example:"true"`
+}
@@ -1,9 +1,28 @@
 package models
 
+import "time"
+
 // AlgoStatuses represents the status of algorithms for a client.
 type AlgoStatuses struct {
-	ClientID int   `json:"client_id,omitempty" example:"123"`
-	VWAP     *bool `json:"vwap,omitempty" example:"true"`
-	TWAP     *bool `json:"twap,omitempty" example:"false"`
-	HFT      *bool `json:"hft,omitempty" example:"true"`
+	ClientID int `json:"client_id,omitempty" example:"123"`
+	// Algorithms maps names of the catalog algorithms to whether they are enabled for the client.
+	Algorithms map[string]bool `json:"algorithms,omitempty"`
+	// Workloads maps names of the algorithms to the observed state of their workloads in the cluster.
+	// It is read-only and reported only when the statuses are fetched.
+	Workloads map[string]WorkloadState `json:"workloads,omitempty"`
+}
+
+// WorkloadState represents the observed state of the workload running a client's algorithm.
+type WorkloadState struct {
+	Pod   string `json:"pod" example:"client-123-vwap"`
+	Phase string `json:"phase,omitempty" example:"Running"`
+	// Reason explains the phase, e.g. Evicted or CrashLoopBackOff.
+	Reason       string `json:"reason,omitempty" example:"CrashLoopBackOff"`
+	RestartCount int    `json:"restart_count" example:"0"`
+	Image        string `json:"image,omitempty" example:"registry.example.com/algo:1.2"`
+	Version      int    `json:"version" example:"3"`
+	// LastError is the error of the scheduler's last action on the workload.
+	LastError        string     `json:"last_error,omitempty"`
+	LastTransitionAt *time.Time `json:"last_transition_at,omitempty" example:"2024-07-17T14:30:00Z"`
+	UpdatedAt        *time.Time `json:"updated_at,omitempty" example:"2024-07-17T14:30:00Z"`
 }
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// APIKey represents a static key the API clients authenticate with, only its hash is stored.
+type APIKey struct {
+	ID        int
+	Name      string
+	CreatedAt time.Time
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Actions of the audit events.
+const (
+	AuditClientCreate     = "client.create"
+	AuditClientUpdate     = "client.update"
+	AuditClientDelete     = "client.delete"
+	AuditParamsUpdate     = "params.update"
+	AuditAlgorithmsToggle = "algorithms.toggle"
+	AuditCatalogSave      = "catalog.save"
+	// The actions of the scheduler, their actor is audit.SystemActor.
+	AuditClientRestart     = "client.restart"
+	AuditClientRestartFail = "client.restart_fail"
+	AuditWorkloadPhase     = "workload.phase"
+)
+
+// AuditEvent represents a change made through the API or by the scheduler.
+type AuditEvent struct {
+	ID int64 `json:"id" example:"42"`
+	// Actor is the authenticated subject which made the change, system for the scheduler
+	Actor     string `json:"actor" example:"apikey:dashboard"`
+	RequestID string `json:"request_id,omitempty" example:"host/abcdef-000001"`
+	Action    string `json:"action" enums:"client.create,client.update,client.delete,params.update,algorithms.toggle,catalog.save,client.restart,client.restart_fail,workload.phase" example:"algorithms.toggle"`
+	// ClientID is empty for the changes of the algorithm catalog
+	ClientID int64 `json:"client_id,omitempty" example:"1"`
+	// Before and After are the states of the changed object, Before is empty for the created ones and After for the deleted ones
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at" example:"2024-07-17T14:30:00Z"`
+}
+
+// AuditQuery represents the filters and the page of the audit events to list, the newest events come first.
+type AuditQuery struct {
+	// ClientID matches the events of the client, zero matches any
+	ClientID int64
+	// From and To bound the time of the events, inclusively
+	From  *time.Time
+	To    *time.Time
+	Limit int
+	// Cursor continues the listing after the page it was returned with, empty for the first page
+	Cursor string
+}
+
+// AuditPage represents a page of the audit events.
+type AuditPage struct {
+	Events []AuditEvent `json:"events"`
+	// NextCursor fetches the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty" example:"NDI"`
+}
@@ -0,0 +1,69 @@
+package models
+
+// Operations of the client batch items.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+)
+
+// Statuses of the batch items.
+const (
+	// BatchItemOK marks the applied item
+	BatchItemOK = "ok"
+	// BatchItemError marks the item which failed
+	BatchItemError = "error"
+	// BatchItemRolledBack marks the item undone because another item of the atomic batch failed
+	BatchItemRolledBack = "rolled_back"
+	// BatchItemSkipped marks the item not tried because an earlier item of the atomic batch failed
+	BatchItemSkipped = "skipped"
+)
+
+// ClientBatch represents many client creations and updates applied at once.
+// An atomic batch is applied all-or-nothing, otherwise every item is applied independently.
+type ClientBatch struct {
+	Atomic bool              `json:"atomic" example:"true"`
+	Items  []ClientBatchItem `json:"items"`
+}
+
+// ClientBatchItem represents a single operation of the client batch.
+type ClientBatchItem struct {
+	Op     string `json:"op" enums:"create,update" example:"create"`
+	Client Client `json:"client"`
+}
+
+// ClientBatchResult represents the outcome of a single item of the client batch.
+type ClientBatchResult struct {
+	Index  int     `json:"index" example:"0"`
+	Status string  `json:"status" enums:"ok,error,rolled_back,skipped" example:"ok"`
+	Client *Client `json:"client,omitempty"`
+	Error  string  `json:"error,omitempty" example:"Client not found"`
+	Code   string  `json:"code,omitempty" example:"client_not_found"`
+}
+
+// ClientBatchResponse represents the outcome of the client batch.
+type ClientBatchResponse struct {
+	Applied bool                `json:"applied" example:"true"`
+	Results []ClientBatchResult `json:"results"`
+}
+
+// AlgoBatch represents algorithm toggles of many clients applied at once.
+// An atomic batch is applied all-or-nothing, otherwise every item is applied independently.
+type AlgoBatch struct {
+	Atomic bool           `json:"atomic" example:"false"`
+	Items  []AlgoStatuses `json:"items"`
+}
+
+// AlgoBatchResult represents the outcome of a single item of the algorithm batch.
+type AlgoBatchResult struct {
+	Index    int           `json:"index" example:"0"`
+	Status   string        `json:"status" enums:"ok,error,rolled_back,skipped" example:"ok"`
+	Statuses *AlgoStatuses `json:"statuses,omitempty"`
+	Error    string        `json:"error,omitempty" example:"Client not found"`
+	Code     string        `json:"code,omitempty" example:"client_not_found"`
+}
+
+// AlgoBatchResponse represents the outcome of the algorithm batch.
+type AlgoBatchResponse struct {
+	Applied bool              `json:"applied" example:"true"`
+	Results []AlgoBatchResult `json:"results"`
+}
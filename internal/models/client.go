@@ -4,15 +4,38 @@ import "time"
 
 // Client represents information about a client.
 type Client struct {
-	ID          int64     `json:"id,omitempty" example:"1"`
-	ClientName  string    `json:"client_name,omitempty" example:"Client A"`
-	Version     int       `json:"version,omitempty" example:"1"`
-	Image       string    `json:"image,omitempty" example:"client-image:latest"`
-	CPU         string    `json:"cpu,omitempty" example:"2 cores"`
-	Memory      string    `json:"memory,omitempty" example:"4 GB"`
-	Priority    float64   `json:"priority,omitempty" example:"0.75"`
-	NeedRestart *bool     `json:"need_restart,omitempty" example:"false"`
-	SpawnedAt   time.Time `json:"spawned_at,omitempty" example:"2024-07-17T12:00:00Z"`
-	CreatedAt   time.Time `json:"created_at,omitempty" example:"2024-07-01T08:00:00Z"`
-	UpdatedAt   time.Time `json:"updated_at,omitempty" example:"2024-07-17T14:30:00Z"`
+	ID           int64     `json:"id,omitempty" example:"1"`
+	ClientName   string    `json:"client_name,omitempty" example:"Client A"`
+	Version      int       `json:"version,omitempty" example:"1"`
+	Image        string    `json:"image,omitempty" example:"client-image:latest"`
+	CPU          string    `json:"cpu,omitempty" example:"500m"`
+	Memory       string    `json:"memory,omitempty" example:"512Mi"`
+	Priority     float64   `json:"priority,omitempty" example:"0.75"`
+	NeedRestart  *bool     `json:"need_restart,omitempty" example:"false"`
+	RestartError string    `json:"restart_error,omitempty" example:"timed out waiting for pods termination"`
+	SpawnedAt    time.Time `json:"spawned_at,omitempty" example:"2024-07-17T12:00:00Z"`
+	CreatedAt    time.Time `json:"created_at,omitempty" example:"2024-07-01T08:00:00Z"`
+	UpdatedAt    time.Time `json:"updated_at,omitempty" example:"2024-07-17T14:30:00Z"`
+	// Revision is bumped on every update, an update carrying a non-zero revision is applied only if it is still current.
+	Revision int `json:"revision,omitempty" example:"3"`
+}
+
+// ClientPatch represents a partial update of a client, the fields left out are kept as they are.
+type ClientPatch struct {
+	ID          int64    `json:"-"`
+	ClientName  *string  `json:"client_name,omitempty" example:"Client A"`
+	Version     *int     `json:"version,omitempty" example:"2"`
+	Image       *string  `json:"image,omitempty" example:"client-image:latest"`
+	CPU         *string  `json:"cpu,omitempty" example:"500m"`
+	Memory      *string  `json:"memory,omitempty" example:"512Mi"`
+	Priority    *float64 `json:"priority,omitempty" example:"0.75"`
+	NeedRestart *bool    `json:"need_restart,omitempty" example:"true"`
+	// Revision the patch is based on, zero applies the patch to any revision.
+	Revision int `json:"revision,omitempty" example:"3"`
+}
+
+// Empty reports whether the patch changes nothing.
+func (p *ClientPatch) Empty() bool {
+	return p.ClientName == nil && p.Version == nil && p.Image == nil && p.CPU == nil &&
+		p.Memory == nil && p.Priority == nil && p.NeedRestart == nil
 }
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Keys the clients can be sorted by.
+const (
+	ClientSortID        = "id"
+	ClientSortName      = "name"
+	ClientSortCreatedAt = "created_at"
+	ClientSortPriority  = "priority"
+)
+
+// ClientQuery represents the filters, the order and the page of the clients to list.
+// The zero values of the filters match any client.
+type ClientQuery struct {
+	// Name matches the clients whose name contains it, case-insensitively
+	Name string
+	// Algorithm matches the clients which have the algorithm enabled
+	Algorithm   string
+	NeedRestart *bool
+	// CreatedFrom and CreatedTo bound the creation time, inclusively
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// PriorityMin and PriorityMax bound the priority, inclusively
+	PriorityMin *float64
+	PriorityMax *float64
+	// Sort is one of the ClientSort keys, the ties are ordered by the client's ID
+	Sort string
+	Desc bool
+	// Limit is the size of the page
+	Limit int
+	// Cursor continues the listing after the page it was returned with, empty for the first page
+	Cursor string
+}
+
+// ClientPage represents a page of the clients.
+type ClientPage struct {
+	Clients []Client `json:"clients"`
+	// NextCursor fetches the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJzIjoiaWQiLCJpZCI6NTB9"`
+	// Total is the number of the clients matching the filters on all pages
+	Total int `json:"total" example:"120"`
+}
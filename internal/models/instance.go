@@ -0,0 +1,31 @@
+package models
+
+// Phases of the instances the scheduler reacts to.
+const (
+	// PhaseFailed is the phase of a pod terminated in failure, e.g. evicted
+	PhaseFailed = "Failed"
+	// PhaseDeleted is reported for a pod which has been deleted
+	PhaseDeleted = "Deleted"
+)
+
+// ReasonCrashLoopBackOff is reported for a pod whose container keeps crashing.
+// It is recorded, but doesn't trigger a redeploy: the pod recreated from the same spec would crash as well.
+const ReasonCrashLoopBackOff = "CrashLoopBackOff"
+
+// Instance describes an object running a workload in the cluster.
+type Instance struct {
+	Name      string
+	Namespace string
+	ClientID  int
+	Algorithm string
+	Phase     string
+	// Reason explains the phase, e.g. Evicted or CrashLoopBackOff
+	Reason string
+	// RestartCount sums the restarts of the pod's containers
+	RestartCount int
+	Version      int
+	// Image requested for the workload, empty if the default one is used
+	Image string
+	// SpecHash identifies the pod spec the workload was deployed with
+	SpecHash string
+}
@@ -0,0 +1,11 @@
+package models
+
+import "encoding/json"
+
+// AlgoParams represents the parameters of the client's algorithm.
+type AlgoParams struct {
+	ClientID  int    `json:"client_id,omitempty" example:"1"`
+	Algorithm string `json:"algorithm,omitempty" example:"twap"`
+	// Params is a JSON object passed to the algorithm's workload as is.
+	Params json.RawMessage `json:"params" swaggertype:"object"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// Workload describes a single algorithm of a client deployed to the cluster.
+type Workload struct {
+	Name      string
+	ClientID  int
+	Algorithm string
+	Version   int
+	Image     string
+	CPU       string
+	Memory    string
+	Priority  float64
+	// Params is the JSON object of the algorithm's parameters set for the client, empty if none are set
+	Params string
+}
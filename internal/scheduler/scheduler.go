@@ -2,60 +2,159 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"sync-algo/internal/config"
 	"sync-algo/internal/lib/logger/sl"
 	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
 )
 
-// Deployer defines the interface for managing Pods
+// Deployer defines the interface for managing workloads in the cluster
 //
 //go:generate mockgen -source=scheduler.go -destination=../deployer/mock/mock.go -package=mock
 type Deployer interface {
-	CreatePod(name string) error
-	DeletePod(name string) error
-	GetPodList() ([]string, error)
+	Create(ctx context.Context, workload models.Workload) error
+	// Update replaces the running workload with the one matching the spec
+	Update(ctx context.Context, workload models.Workload) error
+	Delete(ctx context.Context, instance models.Instance) error
+	// Image returns the image the workload runs, the default one if the workload has none
+	Image(workload models.Workload) string
+	// SpecHash identifies the spec the workload is deployed with, the instances of other specs are outdated
+	SpecHash(workload models.Workload) string
+	// List returns only the workloads created by the service
+	List(ctx context.Context) ([]models.Instance, error)
+	// Watch calls onChange on changes of the managed pods until the context is cancelled
+	Watch(ctx context.Context, onChange func(instance models.Instance)) error
 }
 
-// Storage defines the interface for fetching algorithm statuses
+// Storage defines the interface for fetching algorithm statuses and clients
 type Storage interface {
 	FetchCurrentStatuses(ctx context.Context) ([]models.AlgoStatuses, error)
+	FetchAlgorithms(ctx context.Context) ([]models.Algorithm, error)
+	FetchCurrentParams(ctx context.Context) ([]models.AlgoParams, error)
+	FetchClients(ctx context.Context) ([]models.Client, error)
+	// CompleteRestart returns storage.ErrStaleRevision if the client was changed since the observed revision
+	CompleteRestart(ctx context.Context, clientID int, revision int) error
+	FailRestart(ctx context.Context, clientID int, reason string) error
+	// Listen sends to notify on changes of the desired state and once right after subscribing,
+	// until the context is cancelled or the connection fails
+	Listen(ctx context.Context, notify chan<- struct{}) error
+	UpdateWorkloadPhase(ctx context.Context, instance models.Instance) error
+	SaveWorkload(ctx context.Context, workload models.Workload) error
+	FailWorkload(ctx context.Context, workload models.Workload, reason string) error
+	DeleteWorkload(ctx context.Context, clientID int, algorithm string) error
 }
 
+// resubscribeInterval is the delay before resubscribing to changes after a failure
+const resubscribeInterval = 5 * time.Second
+
 // Scheduler is responsible for synchronizing the state of algorithms
 type Scheduler struct {
-	storage          Storage
-	log              *slog.Logger
-	previousStatuses map[int64]models.AlgoStatuses
+	cfg     *config.Scheduler
+	storage Storage
+	log     *slog.Logger
 }
 
 // New creates a new Scheduler instance
-func New(log *slog.Logger, storage Storage) *Scheduler {
+func New(log *slog.Logger, cfg *config.Scheduler, storage Storage) *Scheduler {
 	return &Scheduler{
-		storage:          storage,
-		log:              log,
-		previousStatuses: make(map[int64]models.AlgoStatuses),
+		cfg:     cfg,
+		storage: storage,
+		log:     log,
 	}
 }
 
-// Start begins the scheduling process
+// Start begins the scheduling process. It returns once the context is cancelled,
+// aborting the sync in progress and waiting for the subscriptions to stop.
 func (s *Scheduler) Start(ctx context.Context, deployer Deployer) {
-	ticker := time.NewTicker(5 * time.Minute)
+	// Full sync is a safety net for the missed changes
+	ticker := time.NewTicker(s.cfg.SyncInterval)
 	defer ticker.Stop()
 
+	changes := make(chan struct{}, 1)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.listen(ctx, changes)
+	}()
+	go func() {
+		defer wg.Done()
+		s.watch(ctx, deployer, changes)
+	}()
+
+	// The first sync runs as soon as the subscription is established: Listen notifies right after subscribing,
+	// so that neither a restart nor a reconnect leaves the changes made meanwhile unapplied
 	for {
 		select {
 		case <-ticker.C:
 			s.syncAlgorithmStatus(ctx, deployer)
+		case <-changes:
+			s.syncAlgorithmStatus(ctx, deployer)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-// syncAlgorithmStatus synchronizes the state of algorithms with the pod state
+// listen subscribes to storage changes, resubscribing after failures until the context is cancelled
+func (s *Scheduler) listen(ctx context.Context, changes chan<- struct{}) {
+	const op = "scheduler.listen"
+
+	log := s.log.With(slog.String("op", op))
+
+	resubscribe(ctx, log, func() error {
+		return s.storage.Listen(ctx, changes)
+	})
+}
+
+// watch observes the managed pods, resubscribing after failures until the context is cancelled
+func (s *Scheduler) watch(ctx context.Context, deployer Deployer, changes chan<- struct{}) {
+	const op = "scheduler.watch"
+
+	log := s.log.With(slog.String("op", op))
+
+	resubscribe(ctx, log, func() error {
+		return deployer.Watch(ctx, func(instance models.Instance) {
+			s.onPodChange(ctx, instance, changes)
+		})
+	})
+}
+
+// onPodChange records the pod's phase and requests a sync if the pod is gone or failed.
+// A crash looping pod is only recorded, since recreating it from the same spec doesn't fix the crash
+func (s *Scheduler) onPodChange(ctx context.Context, instance models.Instance, changes chan<- struct{}) {
+	const op = "scheduler.onPodChange"
+
+	log := s.log.With(slog.String("op", op), slog.String("pod", instance.Name))
+
+	if err := s.storage.UpdateWorkloadPhase(ctx, instance); err != nil {
+		log.Error("error saving workload phase", sl.Error(err))
+	}
+
+	if instance.Phase == models.PhaseDeleted || instance.Phase == models.PhaseFailed {
+		log.Info("pod needs attention", slog.String("phase", instance.Phase), slog.String("reason", instance.Reason))
+
+		// A sync is already pending if the channel is full
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// syncAlgorithmStatus brings the workloads in the cluster to the state described by the algorithm statuses.
+// It compares the desired set of workloads with the actual one on every call,
+// so it is safe to repeat and recovers from any drift.
+// Cancelling the context aborts the sync between actions.
 func (s *Scheduler) syncAlgorithmStatus(ctx context.Context, deployer Deployer) {
 	const op = "scheduler.syncAlgorithmStatus"
 
@@ -63,32 +162,277 @@ func (s *Scheduler) syncAlgorithmStatus(ctx context.Context, deployer Deployer)
 
 	currentStatuses, err := s.storage.FetchCurrentStatuses(ctx)
 	if err != nil {
-		s.log.Error("error fetching algorithm statuses:", sl.Error(err))
+		log.Error("error fetching algorithm statuses", sl.Error(err))
+		return
+	}
+
+	algorithmList, err := s.storage.FetchAlgorithms(ctx)
+	if err != nil {
+		log.Error("error fetching algorithm catalog", sl.Error(err))
+		return
+	}
+
+	paramList, err := s.storage.FetchCurrentParams(ctx)
+	if err != nil {
+		log.Error("error fetching algorithm parameters", sl.Error(err))
+		return
+	}
+
+	clientList, err := s.storage.FetchClients(ctx)
+	if err != nil {
+		log.Error("error fetching clients", sl.Error(err))
+		return
+	}
+
+	params := make(map[string]string, len(paramList))
+	for _, p := range paramList {
+		params[workloadName(p.ClientID, p.Algorithm)] = string(p.Params)
+	}
+
+	algorithms := make(map[string]models.Algorithm, len(algorithmList))
+	for _, algorithm := range algorithmList {
+		if isEnabled(algorithm.Enabled) {
+			algorithms[algorithm.Name] = algorithm
+		}
+	}
+
+	clients := make(map[int]models.Client, len(clientList))
+	for _, client := range clientList {
+		clients[int(client.ID)] = client
+	}
+
+	// Desired state: a workload for every algorithm enabled both in the catalog and for the client
+	desired := make(map[string]models.Workload)
+	for _, status := range currentStatuses {
+		client, ok := clients[status.ClientID]
+		if !ok {
+			// The client was removed between the reads, its workloads are deleted as orphaned
+			log.Warn("skipping statuses of unknown client", slog.Int("client_id", status.ClientID))
+			continue
+		}
+
+		for name, enabled := range status.Algorithms {
+			algorithm, ok := algorithms[name]
+			if !enabled || !ok {
+				continue
+			}
+
+			workload := models.Workload{
+				Name:      workloadName(status.ClientID, name),
+				ClientID:  status.ClientID,
+				Algorithm: name,
+				Version:   client.Version,
+				Image:     orDefault(client.Image, algorithm.Image),
+				CPU:       orDefault(client.CPU, algorithm.CPU),
+				Memory:    orDefault(client.Memory, algorithm.Memory),
+				Priority:  client.Priority,
+			}
+			workload.Params = params[workload.Name]
+			desired[workload.Name] = workload
+		}
+	}
+
+	// Restart clients which asked for it
+	for _, client := range clientList {
+		if !isEnabled(client.NeedRestart) {
+			continue
+		}
+
+		if aborted(ctx, log) {
+			return
+		}
+
+		var workloads []models.Workload
+		for _, workload := range desired {
+			if workload.ClientID == int(client.ID) {
+				workloads = append(workloads, workload)
+			}
+		}
+
+		s.restartClient(ctx, deployer, client, workloads)
+	}
+
+	if aborted(ctx, log) {
+		return
+	}
+
+	instances, err := deployer.List(ctx)
+	if err != nil {
+		log.Error("error fetching workload list", sl.Error(err))
 		return
 	}
 
-	for _, currentStatus := range currentStatuses {
-		previousStatus, exists := s.previousStatuses[int64(currentStatus.ClientID)]
+	// Actual state: workloads created by the scheduler
+	actual := make(map[string]models.Instance, len(instances))
+	for _, instance := range instances {
+		actual[instance.Name] = instance
+	}
 
-		// Create or update the pod if status has changed to enabled
-		if (*currentStatus.VWAP || *currentStatus.TWAP || *currentStatus.HFT) &&
-			(!exists || !*previousStatus.VWAP && !*previousStatus.TWAP && !*previousStatus.HFT) {
-			podName := fmt.Sprintf("client-%d-pod", currentStatus.ClientID)
-			if err := deployer.CreatePod(podName); err != nil {
-				log.Error("error creating pod", sl.Error(err))
+	// Create missing workloads and update outdated ones
+	for name, workload := range desired {
+		if aborted(ctx, log) {
+			return
+		}
+
+		instance, ok := actual[name]
+		if !ok {
+			err := deployer.Create(ctx, workload)
+			s.recordAction(ctx, deployer, workload, err)
+			if err != nil {
+				log.Error("error creating workload", slog.String("workload", name), sl.Error(err))
+				continue
 			}
+
+			log.Info("workload created", slog.String("workload", name))
+			continue
 		}
 
-		// Delete the pod if status has changed to disabled
-		if !*currentStatus.VWAP && !*currentStatus.TWAP && !*currentStatus.HFT &&
-			exists && (*previousStatus.VWAP || *previousStatus.TWAP || *previousStatus.HFT) {
-			podName := fmt.Sprintf("client-%d-pod", currentStatus.ClientID)
-			if err := deployer.DeletePod(podName); err != nil {
-				log.Error("error deleting pod:", sl.Error(err))
+		// Replace workloads running an outdated version or spec, e.g. with changed image, parameters or resources,
+		// and the failed ones, e.g. evicted
+		if instance.Version != workload.Version || instance.Phase == models.PhaseFailed ||
+			instance.SpecHash != deployer.SpecHash(workload) {
+			err := deployer.Update(ctx, workload)
+			s.recordAction(ctx, deployer, workload, err)
+			if err != nil {
+				log.Error("error redeploying workload", slog.String("workload", name), sl.Error(err))
+				continue
 			}
+
+			log.Info("workload redeployed", slog.String("workload", name), slog.Int("version", workload.Version))
+		}
+	}
+
+	// Delete orphaned workloads
+	for name, instance := range actual {
+		if _, ok := desired[name]; ok {
+			continue
 		}
 
-		// Update the previous status
-		s.previousStatuses[int64(currentStatus.ClientID)] = currentStatus
+		if aborted(ctx, log) {
+			return
+		}
+
+		if err := deployer.Delete(ctx, instance); err != nil {
+			log.Error("error deleting workload", slog.String("workload", name), sl.Error(err))
+			continue
+		}
+
+		if err := s.storage.DeleteWorkload(ctx, instance.ClientID, instance.Algorithm); err != nil {
+			log.Error("error deleting workload state", slog.String("workload", name), sl.Error(err))
+		}
+
+		log.Info("workload deleted", slog.String("workload", name))
 	}
 }
+
+// restartClient redeploys the client's workloads and records the outcome in storage
+func (s *Scheduler) restartClient(ctx context.Context, deployer Deployer, client models.Client, workloads []models.Workload) {
+	const op = "scheduler.restartClient"
+
+	clientID := int(client.ID)
+
+	log := s.log.With(slog.String("op", op), slog.Int("client_id", clientID))
+
+	for _, workload := range workloads {
+		err := deployer.Update(ctx, workload)
+		s.recordAction(ctx, deployer, workload, err)
+		if err != nil {
+			// Leave the restart pending, it is retried on the next sync
+			if ctx.Err() != nil {
+				log.Info("client restart aborted", sl.Error(err))
+				return
+			}
+
+			log.Error("client restart failed", slog.String("workload", workload.Name), sl.Error(err))
+
+			reason := fmt.Sprintf("failed to restart %s: %s", workload.Name, err)
+			if err := s.storage.FailRestart(ctx, clientID, reason); err != nil {
+				log.Error("error saving restart failure", sl.Error(err))
+			}
+			return
+		}
+	}
+
+	// The flag stays set if the client was changed during the restart, so the restart requested meanwhile isn't lost
+	err := s.storage.CompleteRestart(ctx, clientID, client.Revision)
+	if errors.Is(err, storage.ErrStaleRevision) {
+		log.Info("client changed during restart, restarting again on the next sync")
+		return
+	}
+	if err != nil {
+		log.Error("error completing restart", sl.Error(err))
+		return
+	}
+
+	log.Info("client restarted", slog.Int("workloads", len(workloads)))
+}
+
+// recordAction saves the outcome of the action on the workload, unless the action was aborted.
+// The deployed workload is saved with the image it actually runs.
+func (s *Scheduler) recordAction(ctx context.Context, deployer Deployer, workload models.Workload, actionErr error) {
+	const op = "scheduler.recordAction"
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	log := s.log.With(slog.String("op", op), slog.String("workload", workload.Name))
+
+	var err error
+	if actionErr != nil {
+		err = s.storage.FailWorkload(ctx, workload, actionErr.Error())
+	} else {
+		workload.Image = deployer.Image(workload)
+		err = s.storage.SaveWorkload(ctx, workload)
+	}
+	if err != nil {
+		log.Error("error saving workload state", sl.Error(err))
+	}
+}
+
+// resubscribe runs the subscription again after failures until the context is cancelled
+func resubscribe(ctx context.Context, log *slog.Logger, subscribe func() error) {
+	for {
+		err := subscribe()
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Error("subscription failed", sl.Error(err))
+
+		select {
+		case <-time.After(resubscribeInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// aborted reports whether the sync was cancelled, e.g. on shutdown
+func aborted(ctx context.Context, log *slog.Logger) bool {
+	if ctx.Err() == nil {
+		return false
+	}
+
+	log.Info("sync aborted", sl.Error(ctx.Err()))
+	return true
+}
+
+// workloadName returns the name of the workload running the client's algorithm
+func workloadName(clientID int, algorithm string) string {
+	return fmt.Sprintf("client-%d-%s", clientID, algorithm)
+}
+
+// orDefault returns the client's own value, falling back to the catalog's default
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+
+	return value
+}
+
+// isEnabled reports whether the flag is set and true
+func isEnabled(status *bool) bool {
+	return status != nil && *status
+}
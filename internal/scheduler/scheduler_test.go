@@ -0,0 +1,582 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"sync-algo/internal/config"
+	"sync-algo/internal/deployer/mock"
+	"sync-algo/internal/lib/logger/handlers/slogdiscard"
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_Start(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := mock.NewMockStorage(ctrl)
+	deployer := mock.NewMockDeployer(ctrl)
+
+	// Subscribing is notified as a change, running the first sync
+	storage.EXPECT().Listen(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, notify chan<- struct{}) error {
+			notify <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	deployer.EXPECT().Watch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, onChange func(models.Instance)) error {
+			<-ctx.Done()
+			return nil
+		})
+	// The only sync, there is no separate one on start
+	storage.EXPECT().FetchCurrentStatuses(gomock.Any()).
+		DoAndReturn(func(ctx context.Context) ([]models.AlgoStatuses, error) {
+			cancel()
+			return nil, ctx.Err()
+		})
+
+	log := slogdiscard.NewDiscardLogger()
+	scheduler := New(log, &config.Scheduler{SyncInterval: time.Hour}, storage)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scheduler.Start(ctx, deployer)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not sync on change")
+	}
+}
+
+// catalog enables all the algorithms without defaults of their own
+var catalog = []models.Algorithm{
+	{Name: "vwap", Enabled: boolPtr(true)},
+	{Name: "twap", Enabled: boolPtr(true)},
+	{Name: "hft", Enabled: boolPtr(true)},
+}
+
+// clients are the records of the clients without settings of their own
+var clients = []models.Client{{ID: 1}, {ID: 2}}
+
+func TestScheduler_syncAlgorithmStatus(t *testing.T) {
+	type mockBehavior func(s *mock.MockStorage, d *mock.MockDeployer)
+
+	tt := []struct {
+		name         string
+		mockBehavior mockBehavior
+	}{
+		{
+			name: "Create workload per enabled algorithm",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{}, nil)
+				d.EXPECT().Create(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(nil)
+				d.EXPECT().Create(gomock.Any(), models.Workload{Name: "client-1-hft", ClientID: 1, Algorithm: "hft"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-hft", ClientID: 1, Algorithm: "hft"}).Return(nil)
+			},
+		},
+		{
+			name: "Create workload from client record",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"twap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, Version: 3, Image: "algo:v2", CPU: "500m", Memory: "512Mi", Priority: 0.75},
+				}, nil)
+				workload := models.Workload{
+					Name:      "client-1-twap",
+					ClientID:  1,
+					Algorithm: "twap",
+					Version:   3,
+					Image:     "algo:v2",
+					CPU:       "500m",
+					Memory:    "512Mi",
+					Priority:  0.75,
+				}
+				d.EXPECT().List(gomock.Any()).Return(nil, nil)
+				d.EXPECT().Create(gomock.Any(), workload).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), workload).Return(nil)
+			},
+		},
+		{
+			name: "Save default image of created workload",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				workload := models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}
+				d.EXPECT().List(gomock.Any()).Return(nil, nil)
+				d.EXPECT().Create(gomock.Any(), workload).Return(nil)
+				d.EXPECT().Image(workload).Return("default-image")
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Image: "default-image"}).Return(nil)
+			},
+		},
+		{
+			name: "Create workload from catalog defaults",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"pov": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return([]models.Algorithm{
+					{Name: "pov", Image: "pov:v1", CPU: "250m", Memory: "256Mi", Enabled: boolPtr(true)},
+				}, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, Version: 2, Memory: "1Gi"},
+				}, nil)
+				workload := models.Workload{
+					Name:      "client-1-pov",
+					ClientID:  1,
+					Algorithm: "pov",
+					Version:   2,
+					Image:     "pov:v1",
+					CPU:       "250m",
+					Memory:    "1Gi",
+				}
+				d.EXPECT().List(gomock.Any()).Return(nil, nil)
+				d.EXPECT().Create(gomock.Any(), workload).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), workload).Return(nil)
+			},
+		},
+		{
+			name: "Delete workload of algorithm disabled in catalog",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "iceberg": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return([]models.Algorithm{
+					{Name: "vwap", Enabled: boolPtr(true)},
+					{Name: "iceberg", Enabled: boolPtr(false)},
+				}, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{
+					{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"},
+					{Name: "client-1-iceberg", ClientID: 1, Algorithm: "iceberg"},
+				}, nil)
+				d.EXPECT().Delete(gomock.Any(), models.Instance{Name: "client-1-iceberg", ClientID: 1, Algorithm: "iceberg"}).Return(nil)
+				s.EXPECT().DeleteWorkload(gomock.Any(), 1, "iceberg").Return(nil)
+			},
+		},
+		{
+			name: "Redeploy workload with changed parameters",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return([]models.AlgoParams{
+					{ClientID: 1, Algorithm: "vwap", Params: json.RawMessage(`{"venues": ["XNYS"]}`)},
+					{ClientID: 1, Algorithm: "twap", Params: json.RawMessage(`{"slice_interval": "30s"}`)},
+				}, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				vwap := models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Params: `{"venues": ["XNYS"]}`}
+				twap := models.Workload{Name: "client-1-twap", ClientID: 1, Algorithm: "twap", Params: `{"slice_interval": "30s"}`}
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{
+					{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", SpecHash: "vwap-spec"},
+					{Name: "client-1-twap", ClientID: 1, Algorithm: "twap", SpecHash: "twap-spec"},
+				}, nil)
+				d.EXPECT().SpecHash(vwap).Return("vwap-spec")
+				d.EXPECT().SpecHash(twap).Return("changed-twap-spec")
+				d.EXPECT().Update(gomock.Any(), twap).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), twap).Return(nil)
+			},
+		},
+		{
+			name: "Create only missing algorithm workload",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{{Name: "client-1-vwap"}}, nil)
+				d.EXPECT().Create(gomock.Any(), models.Workload{Name: "client-1-hft", ClientID: 1, Algorithm: "hft"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-hft", ClientID: 1, Algorithm: "hft"}).Return(nil)
+			},
+		},
+		{
+			name: "Delete orphaned workloads",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": false}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{
+					{Name: "client-1-vwap", Namespace: "default", ClientID: 1, Algorithm: "vwap"},
+					{Name: "client-1-hft", Namespace: "default", ClientID: 1, Algorithm: "hft"},
+					{Name: "client-2-twap", Namespace: "default", ClientID: 2, Algorithm: "twap"},
+				}, nil)
+				d.EXPECT().Delete(gomock.Any(), models.Instance{Name: "client-1-hft", Namespace: "default", ClientID: 1, Algorithm: "hft"}).Return(nil)
+				s.EXPECT().DeleteWorkload(gomock.Any(), 1, "hft").Return(nil)
+				d.EXPECT().Delete(gomock.Any(), models.Instance{Name: "client-2-twap", Namespace: "default", ClientID: 2, Algorithm: "twap"}).Return(nil)
+				s.EXPECT().DeleteWorkload(gomock.Any(), 2, "twap").Return(nil)
+			},
+		},
+		{
+			name: "Skip statuses of removed client",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true}},
+					{ClientID: 3, Algorithms: map[string]bool{"vwap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{
+					{Name: "client-1-vwap"},
+					{Name: "client-3-vwap", ClientID: 3, Algorithm: "vwap"},
+				}, nil)
+				d.EXPECT().Delete(gomock.Any(), models.Instance{Name: "client-3-vwap", ClientID: 3, Algorithm: "vwap"}).Return(nil)
+				s.EXPECT().DeleteWorkload(gomock.Any(), 3, "vwap").Return(nil)
+			},
+		},
+		{
+			name: "Continue after create error",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"hft": true}},
+					{ClientID: 2, Algorithms: map[string]bool{"twap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				d.EXPECT().List(gomock.Any()).Return(nil, nil)
+				d.EXPECT().Create(gomock.Any(), models.Workload{Name: "client-1-hft", ClientID: 1, Algorithm: "hft"}).Return(errors.New("kubernetes error"))
+				s.EXPECT().FailWorkload(gomock.Any(), models.Workload{Name: "client-1-hft", ClientID: 1, Algorithm: "hft"}, "kubernetes error").Return(nil)
+				d.EXPECT().Create(gomock.Any(), models.Workload{Name: "client-2-twap", ClientID: 2, Algorithm: "twap"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-2-twap", ClientID: 2, Algorithm: "twap"}).Return(nil)
+			},
+		},
+		{
+			name: "Redeploy workloads after version bump",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "hft": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, Version: 2, Image: "algo:v1"},
+				}, nil)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{
+					{Name: "client-1-vwap", Version: 1, Image: "algo:v1"},
+					{Name: "client-1-hft", Version: 2, Image: "algo:v1"},
+				}, nil)
+				d.EXPECT().Update(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Version: 2, Image: "algo:v1"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Version: 2, Image: "algo:v1"}).Return(nil)
+			},
+		},
+		{
+			name: "Redeploy workloads after image change",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"twap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, Version: 1},
+				}, nil)
+				workload := models.Workload{Name: "client-1-twap", ClientID: 1, Algorithm: "twap", Version: 1}
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{
+					{Name: "client-1-twap", Version: 1, Image: "algo:v1", SpecHash: "algo-v1-spec"},
+				}, nil)
+				d.EXPECT().SpecHash(workload).Return("default-image-spec")
+				d.EXPECT().Update(gomock.Any(), workload).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), workload).Return(nil)
+			},
+		},
+		{
+			name: "Redeploy workload after resources change",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"twap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, Version: 1, CPU: "1", Memory: "1Gi", Priority: 0.9},
+				}, nil)
+				workload := models.Workload{
+					Name: "client-1-twap", ClientID: 1, Algorithm: "twap", Version: 1, CPU: "1", Memory: "1Gi", Priority: 0.9,
+				}
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{
+					{Name: "client-1-twap", Version: 1, SpecHash: "small-spec"},
+				}, nil)
+				d.EXPECT().SpecHash(workload).Return("large-spec")
+				d.EXPECT().Update(gomock.Any(), workload).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), workload).Return(nil)
+			},
+		},
+		{
+			name: "Recreate failed workload",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"twap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{
+					{Name: "client-1-twap", Phase: models.PhaseFailed, Reason: "Evicted"},
+				}, nil)
+				d.EXPECT().Update(gomock.Any(), models.Workload{Name: "client-1-twap", ClientID: 1, Algorithm: "twap"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-twap", ClientID: 1, Algorithm: "twap"}).Return(nil)
+			},
+		},
+		{
+			name: "Restart client",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, NeedRestart: boolPtr(true), Revision: 3},
+				}, nil)
+				d.EXPECT().Update(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(nil)
+				s.EXPECT().CompleteRestart(gomock.Any(), 1, 3).Return(nil)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{{Name: "client-1-vwap"}}, nil)
+			},
+		},
+		{
+			name: "Restart requested again during restart",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, NeedRestart: boolPtr(true), Revision: 3},
+				}, nil)
+				d.EXPECT().Update(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(nil)
+				// The flag stays set, neither FailRestart nor another attempt is expected within this sync
+				s.EXPECT().CompleteRestart(gomock.Any(), 1, 3).Return(storage.ErrStaleRevision)
+				d.EXPECT().List(gomock.Any()).Return([]models.Instance{{Name: "client-1-vwap"}}, nil)
+			},
+		},
+		{
+			name: "Restart client without enabled algorithms",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": false}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, NeedRestart: boolPtr(true), Revision: 1},
+				}, nil)
+				s.EXPECT().CompleteRestart(gomock.Any(), 1, 1).Return(nil)
+				d.EXPECT().List(gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name: "Restart failed",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return([]models.Client{
+					{ID: 1, NeedRestart: boolPtr(true)},
+				}, nil)
+				d.EXPECT().Update(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(errors.New("kubernetes error"))
+				s.EXPECT().FailWorkload(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}, "kubernetes error").Return(nil)
+				s.EXPECT().FailRestart(gomock.Any(), 1, "failed to restart client-1-vwap: kubernetes error").Return(nil)
+				d.EXPECT().List(gomock.Any()).Return(nil, nil)
+				d.EXPECT().Create(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(nil)
+				s.EXPECT().SaveWorkload(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).Return(nil)
+			},
+		},
+		{
+			name: "Storage error",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return(nil, errors.New("internal storage error"))
+			},
+		},
+		{
+			name: "Deployer error",
+			mockBehavior: func(s *mock.MockStorage, d *mock.MockDeployer) {
+				s.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+					{ClientID: 1, Algorithms: map[string]bool{"vwap": true}},
+				}, nil)
+				s.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+				s.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+				s.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+				d.EXPECT().List(gomock.Any()).Return(nil, errors.New("kubernetes error"))
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := mock.NewMockStorage(ctrl)
+			deployer := mock.NewMockDeployer(ctrl)
+			tc.mockBehavior(storage, deployer)
+			// The instances without a spec hash match their workloads unless the case expects otherwise
+			deployer.EXPECT().SpecHash(gomock.Any()).Return("").AnyTimes()
+			// The workloads run their own images unless the case expects the default one
+			deployer.EXPECT().Image(gomock.Any()).DoAndReturn(func(workload models.Workload) string { return workload.Image }).AnyTimes()
+
+			log := slogdiscard.NewDiscardLogger()
+			scheduler := New(log, &config.Scheduler{SyncInterval: time.Hour}, storage)
+
+			// Test method
+			scheduler.syncAlgorithmStatus(context.Background(), deployer)
+		})
+	}
+}
+
+func TestScheduler_onPodChange(t *testing.T) {
+	tt := []struct {
+		name       string
+		instance   models.Instance
+		saveErr    error
+		wantNotify bool
+	}{
+		{
+			name:     "Running pod",
+			instance: models.Instance{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Phase: "Running"},
+		},
+		{
+			name:       "Deleted pod",
+			instance:   models.Instance{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Phase: models.PhaseDeleted},
+			wantNotify: true,
+		},
+		{
+			name:       "Evicted pod",
+			instance:   models.Instance{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Phase: models.PhaseFailed, Reason: "Evicted"},
+			wantNotify: true,
+		},
+		{
+			name:     "Crashing pod",
+			instance: models.Instance{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Phase: "Running", Reason: models.ReasonCrashLoopBackOff},
+		},
+		{
+			name:       "Storage error",
+			instance:   models.Instance{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap", Phase: models.PhaseDeleted},
+			saveErr:    errors.New("internal storage error"),
+			wantNotify: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := mock.NewMockStorage(ctrl)
+			storage.EXPECT().UpdateWorkloadPhase(gomock.Any(), tc.instance).Return(tc.saveErr)
+
+			log := slogdiscard.NewDiscardLogger()
+			scheduler := New(log, &config.Scheduler{SyncInterval: time.Hour}, storage)
+
+			// Test method
+			changes := make(chan struct{}, 1)
+			scheduler.onPodChange(context.Background(), tc.instance, changes)
+
+			// Assert
+			assert.Equal(t, tc.wantNotify, len(changes) == 1)
+		})
+	}
+}
+
+func TestScheduler_syncAlgorithmStatus_cancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := mock.NewMockStorage(ctrl)
+	deployer := mock.NewMockDeployer(ctrl)
+
+	storage.EXPECT().FetchCurrentStatuses(gomock.Any()).Return([]models.AlgoStatuses{
+		{ClientID: 1, Algorithms: map[string]bool{"vwap": true}},
+	}, nil)
+	storage.EXPECT().FetchAlgorithms(gomock.Any()).Return(catalog, nil)
+	storage.EXPECT().FetchCurrentParams(gomock.Any()).Return(nil, nil)
+	storage.EXPECT().FetchClients(gomock.Any()).Return(clients, nil)
+	deployer.EXPECT().List(gomock.Any()).Return([]models.Instance{{Name: "client-2-twap"}}, nil)
+	// Shutdown while the workload is being created, the orphan must survive until the next sync
+	deployer.EXPECT().Create(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).
+		DoAndReturn(func(ctx context.Context, workload models.Workload) error {
+			cancel()
+			return ctx.Err()
+		})
+
+	log := slogdiscard.NewDiscardLogger()
+	scheduler := New(log, &config.Scheduler{SyncInterval: time.Hour}, storage)
+
+	scheduler.syncAlgorithmStatus(ctx, deployer)
+}
+
+func TestScheduler_restartClient_cancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	storage := mock.NewMockStorage(ctrl)
+	deployer := mock.NewMockDeployer(ctrl)
+
+	// Neither CompleteRestart nor FailRestart is expected, the restart stays pending
+	deployer.EXPECT().Update(gomock.Any(), models.Workload{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"}).
+		DoAndReturn(func(ctx context.Context, workload models.Workload) error {
+			cancel()
+			return ctx.Err()
+		})
+
+	log := slogdiscard.NewDiscardLogger()
+	scheduler := New(log, &config.Scheduler{SyncInterval: time.Hour}, storage)
+
+	scheduler.restartClient(ctx, deployer, models.Client{ID: 1, NeedRestart: boolPtr(true)}, []models.Workload{
+		{Name: "client-1-vwap", ClientID: 1, Algorithm: "vwap"},
+		{Name: "client-1-hft", ClientID: 1, Algorithm: "hft"},
+	})
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
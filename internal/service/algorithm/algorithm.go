@@ -2,14 +2,16 @@ package algorithm
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 	"sync-algo/internal/lib/logger/sl"
 	"sync-algo/internal/models"
 )
 
 type Storage interface {
-	UpdateStatuses(ctx context.Context, fields []string, values []interface{}) (*models.AlgoStatuses, error)
+	UpdateStatuses(ctx context.Context, algoStatuses *models.AlgoStatuses) (*models.AlgoStatuses, error)
+	FetchAlgorithms(ctx context.Context) ([]models.Algorithm, error)
+	SaveAlgorithm(ctx context.Context, algorithm *models.Algorithm) (*models.Algorithm, error)
+	ApplyStatusesBatch(ctx context.Context, items []models.AlgoStatuses, atomic bool) ([]*models.AlgoStatuses, []error, error)
 }
 
 type Service struct {
@@ -29,35 +31,64 @@ func (s *Service) UpdateStatuses(ctx context.Context, algoStatuses *models.AlgoS
 
 	log := s.log.With(slog.String("op", op))
 
-	var fields []string
-	var values []interface{}
-	order := 1
+	log.Debug("Payload", slog.Int("client_id", algoStatuses.ClientID), slog.Any("algorithms", algoStatuses.Algorithms))
 
-	if algoStatuses.HFT != nil {
-		fields = append(fields, fmt.Sprintf("hft = $%d", order))
-		values = append(values, fmt.Sprint(*algoStatuses.HFT))
-		order++
+	updatedAlgoStatuses, err := s.storage.UpdateStatuses(ctx, algoStatuses)
+	if err != nil {
+		log.Error("failed to update algorithms", sl.Error(err))
+		return nil, err
 	}
-	if algoStatuses.TWAP != nil {
-		fields = append(fields, fmt.Sprintf("twap = $%d", order))
-		values = append(values, fmt.Sprint(*algoStatuses.TWAP))
-		order++
+
+	return updatedAlgoStatuses, nil
+}
+
+// ApplyBatch toggles algorithms of many clients at once, returning the resulting statuses and the error of every item
+func (s *Service) ApplyBatch(ctx context.Context, batch *models.AlgoBatch) ([]*models.AlgoStatuses, []error, error) {
+	const op = "service.algorithm.ApplyBatch"
+
+	log := s.log.With(slog.String("op", op), slog.Int("items", len(batch.Items)), slog.Bool("atomic", batch.Atomic))
+
+	statuses, errs, err := s.storage.ApplyStatusesBatch(ctx, batch.Items, batch.Atomic)
+	if err != nil {
+		log.Error("failed to apply algorithm batch", sl.Error(err))
+		return statuses, errs, err
 	}
-	if algoStatuses.VWAP != nil {
-		fields = append(fields, fmt.Sprintf("vwap = $%d", order))
-		values = append(values, fmt.Sprint(*algoStatuses.VWAP))
-		order++
+
+	return statuses, errs, nil
+}
+
+// ListAlgorithms returns the algorithm catalog
+func (s *Service) ListAlgorithms(ctx context.Context) ([]models.Algorithm, error) {
+	const op = "service.algorithm.ListAlgorithms"
+
+	log := s.log.With(slog.String("op", op))
+
+	algorithms, err := s.storage.FetchAlgorithms(ctx)
+	if err != nil {
+		log.Error("failed to fetch algorithms", sl.Error(err))
+		return nil, err
+	}
+
+	if algorithms == nil {
+		algorithms = []models.Algorithm{}
 	}
 
-	values = append(values, algoStatuses.ClientID)
+	return algorithms, nil
+}
+
+// SaveAlgorithm adds the algorithm to the catalog or replaces its defaults
+func (s *Service) SaveAlgorithm(ctx context.Context, algorithm *models.Algorithm) (*models.Algorithm, error) {
+	const op = "service.algorithm.SaveAlgorithm"
 
-	log.Debug("Payload", "fields", fields, "values", values)
+	log := s.log.With(slog.String("op", op), slog.String("algorithm", algorithm.Name))
 
-	updatedAlgoStatuses, err := s.storage.UpdateStatuses(ctx, fields, values)
+	saved, err := s.storage.SaveAlgorithm(ctx, algorithm)
 	if err != nil {
-		log.Error("failed to update algorithms", sl.Error(err))
+		log.Error("failed to save algorithm", sl.Error(err))
 		return nil, err
 	}
 
-	return updatedAlgoStatuses, nil
+	log.Info("algorithm saved", slog.Bool("enabled", saved.Enabled != nil && *saved.Enabled))
+
+	return saved, nil
 }
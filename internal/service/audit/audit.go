@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+
+	"sync-algo/internal/lib/logger/sl"
+	"sync-algo/internal/models"
+)
+
+// Storage reads the audit events, they are written by the storage along with the changes
+type Storage interface {
+	FetchAuditEvents(ctx context.Context, query *models.AuditQuery) (*models.AuditPage, error)
+}
+
+type Service struct {
+	storage Storage
+	log     *slog.Logger
+}
+
+func New(storage Storage, log *slog.Logger) *Service {
+	return &Service{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// ListEvents returns a page of the audit events matching the query
+func (s *Service) ListEvents(ctx context.Context, query *models.AuditQuery) (*models.AuditPage, error) {
+	const op = "service.audit.ListEvents"
+
+	log := s.log.With(slog.String("op", op))
+
+	page, err := s.storage.FetchAuditEvents(ctx, query)
+	if err != nil {
+		log.Error("failed to fetch audit events", sl.Error(err))
+		return nil, err
+	}
+
+	// Render an empty list rather than null
+	if page.Events == nil {
+		page.Events = []models.AuditEvent{}
+	}
+
+	return page, nil
+}
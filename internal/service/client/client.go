@@ -2,30 +2,50 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
+	"sync-algo/internal/lib/apperr"
 	"sync-algo/internal/lib/logger/sl"
 	"sync-algo/internal/models"
 )
 
 const emptyValue = 0
 
+// ErrCluster is returned when the client's namespace can't be managed in the cluster
+var ErrCluster = apperr.New(apperr.KindUnavailable, "cluster_unavailable", "cluster unavailable")
+
 //go:generate mockgen -source=client.go -destination=mock/mock.go -package=mock_storage
 type Storage interface {
 	CreateClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error)
-	UpdateClient(ctx context.Context, clientInfo *models.Client) error
+	UpdateClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error)
+	PatchClient(ctx context.Context, patch *models.ClientPatch) (*models.Client, error)
 	RemoveClient(ctx context.Context, id int) error
+	FetchClientPage(ctx context.Context, query *models.ClientQuery) (*models.ClientPage, error)
+	FetchClient(ctx context.Context, id int) (*models.Client, error)
+	FetchStatuses(ctx context.Context, clientID int) (*models.AlgoStatuses, error)
+	ApplyClientBatch(ctx context.Context, items []models.ClientBatchItem, atomic bool) ([]*models.Client, []error, error)
+	FetchParams(ctx context.Context, clientID int, algorithm string) (*models.AlgoParams, error)
+	UpdateParams(ctx context.Context, algoParams *models.AlgoParams) (*models.AlgoParams, error)
+}
+
+// Namespaces manages the clients' namespaces in the cluster
+type Namespaces interface {
+	CreateNamespace(ctx context.Context, clientID int) error
+	DeleteNamespace(ctx context.Context, clientID int) error
 }
 
 type Service struct {
-	storage Storage
-	log     *slog.Logger
+	storage    Storage
+	namespaces Namespaces
+	log        *slog.Logger
 }
 
-func New(storage Storage, log *slog.Logger) *Service {
+func New(storage Storage, namespaces Namespaces, log *slog.Logger) *Service {
 	return &Service{
-		storage: storage,
-		log:     log,
+		storage:    storage,
+		namespaces: namespaces,
+		log:        log,
 	}
 }
 func (s *Service) AddClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error) {
@@ -39,21 +59,47 @@ func (s *Service) AddClient(ctx context.Context, clientInfo *models.Client) (*mo
 		return nil, err
 	}
 
+	err = s.namespaces.CreateNamespace(ctx, int(client.ID))
+	if err != nil {
+		log.Error("failed to create client's namespace", sl.Error(err))
+
+		// Don't keep the client which can't be deployed
+		if err := s.storage.RemoveClient(ctx, int(client.ID)); err != nil {
+			log.Error("failed to remove client", sl.Error(err))
+		}
+		return nil, fmt.Errorf("%w: %w", ErrCluster, err)
+	}
+
 	return client, nil
 }
 
-func (s *Service) UpdateClient(ctx context.Context, clientInfo *models.Client) error {
+func (s *Service) UpdateClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error) {
 	const op = "service.client.UpdateClient"
 
 	log := s.log.With(slog.String("op", op))
 
-	err := s.storage.UpdateClient(ctx, clientInfo)
+	client, err := s.storage.UpdateClient(ctx, clientInfo)
 	if err != nil {
 		log.Error("failed to update client", sl.Error(err))
-		return err
+		return nil, err
 	}
 
-	return nil
+	return client, nil
+}
+
+// PatchClient changes only the client's fields set in the patch
+func (s *Service) PatchClient(ctx context.Context, patch *models.ClientPatch) (*models.Client, error) {
+	const op = "service.client.PatchClient"
+
+	log := s.log.With(slog.String("op", op))
+
+	client, err := s.storage.PatchClient(ctx, patch)
+	if err != nil {
+		log.Error("failed to patch client", sl.Error(err))
+		return nil, err
+	}
+
+	return client, nil
 }
 
 func (s *Service) DeleteClient(ctx context.Context, clientID int) error {
@@ -61,7 +107,14 @@ func (s *Service) DeleteClient(ctx context.Context, clientID int) error {
 
 	log := s.log.With(slog.String("op", op))
 
-	err := s.storage.RemoveClient(ctx, clientID)
+	// Delete the namespace first, so that a failure leaves the client in place to retry
+	err := s.namespaces.DeleteNamespace(ctx, clientID)
+	if err != nil {
+		log.Error("failed to delete client's namespace", sl.Error(err))
+		return fmt.Errorf("%w: %w", ErrCluster, err)
+	}
+
+	err = s.storage.RemoveClient(ctx, clientID)
 	if err != nil {
 		log.Error("failed to remove client", sl.Error(err))
 		return err
@@ -69,3 +122,113 @@ func (s *Service) DeleteClient(ctx context.Context, clientID int) error {
 
 	return nil
 }
+
+// ListClients returns a page of the clients matching the query
+func (s *Service) ListClients(ctx context.Context, query *models.ClientQuery) (*models.ClientPage, error) {
+	const op = "service.client.ListClients"
+
+	log := s.log.With(slog.String("op", op))
+
+	page, err := s.storage.FetchClientPage(ctx, query)
+	if err != nil {
+		log.Error("failed to fetch clients", sl.Error(err))
+		return nil, err
+	}
+
+	// Render an empty list rather than null
+	if page.Clients == nil {
+		page.Clients = []models.Client{}
+	}
+
+	return page, nil
+}
+
+func (s *Service) GetClient(ctx context.Context, clientID int) (*models.Client, error) {
+	const op = "service.client.GetClient"
+
+	log := s.log.With(slog.String("op", op))
+
+	client, err := s.storage.FetchClient(ctx, clientID)
+	if err != nil {
+		log.Error("failed to fetch client", sl.Error(err))
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (s *Service) GetAlgorithms(ctx context.Context, clientID int) (*models.AlgoStatuses, error) {
+	const op = "service.client.GetAlgorithms"
+
+	log := s.log.With(slog.String("op", op))
+
+	statuses, err := s.storage.FetchStatuses(ctx, clientID)
+	if err != nil {
+		log.Error("failed to fetch algorithm statuses", sl.Error(err))
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// GetParams returns the parameters of the client's algorithm
+func (s *Service) GetParams(ctx context.Context, clientID int, algorithm string) (*models.AlgoParams, error) {
+	const op = "service.client.GetParams"
+
+	log := s.log.With(slog.String("op", op))
+
+	params, err := s.storage.FetchParams(ctx, clientID, algorithm)
+	if err != nil {
+		log.Error("failed to fetch algorithm parameters", sl.Error(err))
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// UpdateParams replaces the parameters of the client's algorithm, the scheduler redeploys only its workload
+func (s *Service) UpdateParams(ctx context.Context, algoParams *models.AlgoParams) (*models.AlgoParams, error) {
+	const op = "service.client.UpdateParams"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("client_id", algoParams.ClientID),
+		slog.String("algorithm", algoParams.Algorithm),
+	)
+
+	params, err := s.storage.UpdateParams(ctx, algoParams)
+	if err != nil {
+		log.Error("failed to update algorithm parameters", sl.Error(err))
+		return nil, err
+	}
+
+	log.Info("algorithm parameters updated")
+
+	return params, nil
+}
+
+// ApplyBatch creates and updates many clients at once, returning the resulting client and the error of every item.
+// Namespaces of the created clients are created afterwards, a failure is left to the deployer to retry.
+func (s *Service) ApplyBatch(ctx context.Context, batch *models.ClientBatch) ([]*models.Client, []error, error) {
+	const op = "service.client.ApplyBatch"
+
+	log := s.log.With(slog.String("op", op), slog.Int("items", len(batch.Items)), slog.Bool("atomic", batch.Atomic))
+
+	clients, errs, err := s.storage.ApplyClientBatch(ctx, batch.Items, batch.Atomic)
+	if err != nil {
+		log.Error("failed to apply client batch", sl.Error(err))
+		return clients, errs, err
+	}
+
+	for i, item := range batch.Items {
+		if item.Op != models.BatchCreate || errs[i] != nil {
+			continue
+		}
+
+		if err := s.namespaces.CreateNamespace(ctx, int(clients[i].ID)); err != nil {
+			log.Error("failed to create client's namespace", slog.Int64("client_id", clients[i].ID), sl.Error(err))
+		}
+	}
+
+	return clients, errs, nil
+}
@@ -3,19 +3,21 @@ package client
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"sync-algo/internal/lib/logger/handlers/slogdiscard"
 	"sync-algo/internal/models"
 	mock_storage "sync-algo/internal/service/client/mock"
+	"sync-algo/internal/storage"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestService_AddClient(t *testing.T) {
-	type mockBehavior func(s *mock_storage.MockStorage, clientInfo *models.Client)
+	type mockBehavior func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientInfo *models.Client)
 
 	tt := []struct {
 		name           string
@@ -27,7 +29,7 @@ func TestService_AddClient(t *testing.T) {
 		{
 			name:        "Successful client creation",
 			inputClient: models.Client{ClientName: "clientName"},
-			mockBehavior: func(s *mock_storage.MockStorage, clientInfo *models.Client) {
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientInfo *models.Client) {
 				s.EXPECT().CreateClient(gomock.Any(), clientInfo).Return(&models.Client{
 					ID:         1,
 					ClientName: "clientName",
@@ -35,6 +37,7 @@ func TestService_AddClient(t *testing.T) {
 					CreatedAt:  time.Time{},
 					UpdatedAt:  time.Time{},
 				}, nil)
+				n.EXPECT().CreateNamespace(gomock.Any(), 1).Return(nil)
 			},
 			expectedClient: &models.Client{
 				ID:         1,
@@ -48,12 +51,32 @@ func TestService_AddClient(t *testing.T) {
 		{
 			name:        "Storage error",
 			inputClient: models.Client{ClientName: "clientName"},
-			mockBehavior: func(s *mock_storage.MockStorage, clientInfo *models.Client) {
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientInfo *models.Client) {
 				s.EXPECT().CreateClient(gomock.Any(), clientInfo).Return(nil, errors.New("internal storage error"))
 			},
 			expectedClient: nil,
 			expectedError:  errors.New("internal storage error"),
 		},
+		{
+			name:        "Duplicate client name",
+			inputClient: models.Client{ClientName: "clientName"},
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientInfo *models.Client) {
+				s.EXPECT().CreateClient(gomock.Any(), clientInfo).Return(nil, storage.ErrExists)
+			},
+			expectedClient: nil,
+			expectedError:  storage.ErrExists,
+		},
+		{
+			name:        "Namespace error",
+			inputClient: models.Client{ClientName: "clientName"},
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientInfo *models.Client) {
+				s.EXPECT().CreateClient(gomock.Any(), clientInfo).Return(&models.Client{ID: 1, ClientName: "clientName"}, nil)
+				n.EXPECT().CreateNamespace(gomock.Any(), 1).Return(errors.New("kubernetes error"))
+				s.EXPECT().RemoveClient(gomock.Any(), 1).Return(nil)
+			},
+			expectedClient: nil,
+			expectedError:  fmt.Errorf("%w: %w", ErrCluster, errors.New("kubernetes error")),
+		},
 	}
 
 	for _, tc := range tt {
@@ -63,10 +86,11 @@ func TestService_AddClient(t *testing.T) {
 			defer ctrl.Finish()
 
 			storage := mock_storage.NewMockStorage(ctrl)
-			tc.mockBehavior(storage, &tc.inputClient)
+			namespaces := mock_storage.NewMockNamespaces(ctrl)
+			tc.mockBehavior(storage, namespaces, &tc.inputClient)
 
 			log := slogdiscard.NewDiscardLogger()
-			service := New(storage, log)
+			service := New(storage, namespaces, log)
 
 			// Test method
 			client, err := service.AddClient(context.Background(), &tc.inputClient)
@@ -79,7 +103,7 @@ func TestService_AddClient(t *testing.T) {
 }
 
 func TestService_DeleteClient(t *testing.T) {
-	type mockBehavior func(s *mock_storage.MockStorage, clientID int)
+	type mockBehavior func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientID int)
 
 	tests := []struct {
 		name          string
@@ -90,7 +114,8 @@ func TestService_DeleteClient(t *testing.T) {
 		{
 			name:     "Successful client deletion",
 			clientID: 1,
-			mockBehavior: func(s *mock_storage.MockStorage, clientID int) {
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientID int) {
+				n.EXPECT().DeleteNamespace(gomock.Any(), clientID).Return(nil)
 				s.EXPECT().RemoveClient(gomock.Any(), clientID).Return(nil)
 			},
 			expectedError: nil,
@@ -98,11 +123,20 @@ func TestService_DeleteClient(t *testing.T) {
 		{
 			name:     "Storage error",
 			clientID: 1,
-			mockBehavior: func(s *mock_storage.MockStorage, clientID int) {
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientID int) {
+				n.EXPECT().DeleteNamespace(gomock.Any(), clientID).Return(nil)
 				s.EXPECT().RemoveClient(gomock.Any(), clientID).Return(errors.New("internal storage error"))
 			},
 			expectedError: errors.New("internal storage error"),
 		},
+		{
+			name:     "Namespace error",
+			clientID: 1,
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, clientID int) {
+				n.EXPECT().DeleteNamespace(gomock.Any(), clientID).Return(errors.New("kubernetes error"))
+			},
+			expectedError: fmt.Errorf("%w: %w", ErrCluster, errors.New("kubernetes error")),
+		},
 	}
 
 	for _, tt := range tests {
@@ -112,10 +146,11 @@ func TestService_DeleteClient(t *testing.T) {
 			defer ctrl.Finish()
 
 			storage := mock_storage.NewMockStorage(ctrl)
-			tt.mockBehavior(storage, tt.clientID)
+			namespaces := mock_storage.NewMockNamespaces(ctrl)
+			tt.mockBehavior(storage, namespaces, tt.clientID)
 
 			log := slogdiscard.NewDiscardLogger()
-			service := New(storage, log)
+			service := New(storage, namespaces, log)
 
 			// Test method
 			err := service.DeleteClient(context.Background(), tt.clientID)
@@ -125,3 +160,317 @@ func TestService_DeleteClient(t *testing.T) {
 		})
 	}
 }
+
+func TestService_ListClients(t *testing.T) {
+	type mockBehavior func(s *mock_storage.MockStorage, query *models.ClientQuery)
+
+	tt := []struct {
+		name          string
+		query         *models.ClientQuery
+		mockBehavior  mockBehavior
+		expectedPage  *models.ClientPage
+		expectedError error
+	}{
+		{
+			name:  "Successful clients listing",
+			query: &models.ClientQuery{Name: "client", Limit: 2},
+			mockBehavior: func(s *mock_storage.MockStorage, query *models.ClientQuery) {
+				s.EXPECT().FetchClientPage(gomock.Any(), query).Return(&models.ClientPage{
+					Clients: []models.Client{
+						{ID: 1, ClientName: "clientA"},
+						{ID: 2, ClientName: "clientB"},
+					},
+					NextCursor: "next",
+					Total:      3,
+				}, nil)
+			},
+			expectedPage: &models.ClientPage{
+				Clients: []models.Client{
+					{ID: 1, ClientName: "clientA"},
+					{ID: 2, ClientName: "clientB"},
+				},
+				NextCursor: "next",
+				Total:      3,
+			},
+		},
+		{
+			name:  "No clients",
+			query: &models.ClientQuery{Limit: 2},
+			mockBehavior: func(s *mock_storage.MockStorage, query *models.ClientQuery) {
+				s.EXPECT().FetchClientPage(gomock.Any(), query).Return(&models.ClientPage{}, nil)
+			},
+			expectedPage: &models.ClientPage{Clients: []models.Client{}},
+		},
+		{
+			name:  "Storage error",
+			query: &models.ClientQuery{Limit: 2},
+			mockBehavior: func(s *mock_storage.MockStorage, query *models.ClientQuery) {
+				s.EXPECT().FetchClientPage(gomock.Any(), query).Return(nil, errors.New("internal storage error"))
+			},
+			expectedError: errors.New("internal storage error"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := mock_storage.NewMockStorage(ctrl)
+			namespaces := mock_storage.NewMockNamespaces(ctrl)
+			tc.mockBehavior(storage, tc.query)
+
+			log := slogdiscard.NewDiscardLogger()
+			service := New(storage, namespaces, log)
+
+			// Test method
+			page, err := service.ListClients(context.Background(), tc.query)
+
+			// Assert results
+			assert.Equal(t, tc.expectedPage, page)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestService_GetClient(t *testing.T) {
+	type mockBehavior func(s *mock_storage.MockStorage, clientID int)
+
+	tt := []struct {
+		name           string
+		clientID       int
+		mockBehavior   mockBehavior
+		expectedClient *models.Client
+		expectedError  error
+	}{
+		{
+			name:     "Successful client fetching",
+			clientID: 1,
+			mockBehavior: func(s *mock_storage.MockStorage, clientID int) {
+				s.EXPECT().FetchClient(gomock.Any(), clientID).Return(&models.Client{ID: 1, ClientName: "clientName"}, nil)
+			},
+			expectedClient: &models.Client{ID: 1, ClientName: "clientName"},
+		},
+		{
+			name:     "Storage error",
+			clientID: 1,
+			mockBehavior: func(s *mock_storage.MockStorage, clientID int) {
+				s.EXPECT().FetchClient(gomock.Any(), clientID).Return(nil, errors.New("internal storage error"))
+			},
+			expectedError: errors.New("internal storage error"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := mock_storage.NewMockStorage(ctrl)
+			namespaces := mock_storage.NewMockNamespaces(ctrl)
+			tc.mockBehavior(storage, tc.clientID)
+
+			log := slogdiscard.NewDiscardLogger()
+			service := New(storage, namespaces, log)
+
+			// Test method
+			client, err := service.GetClient(context.Background(), tc.clientID)
+
+			// Assert results
+			assert.Equal(t, tc.expectedClient, client)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestService_PatchClient(t *testing.T) {
+	type mockBehavior func(s *mock_storage.MockStorage, patch *models.ClientPatch)
+
+	priority := 0.5
+
+	tt := []struct {
+		name           string
+		patch          *models.ClientPatch
+		mockBehavior   mockBehavior
+		expectedClient *models.Client
+		expectedError  error
+	}{
+		{
+			name:  "Successful client patching",
+			patch: &models.ClientPatch{ID: 1, Priority: &priority, Revision: 2},
+			mockBehavior: func(s *mock_storage.MockStorage, patch *models.ClientPatch) {
+				s.EXPECT().PatchClient(gomock.Any(), patch).Return(&models.Client{ID: 1, Image: "image", Priority: 0.5, Revision: 3}, nil)
+			},
+			expectedClient: &models.Client{ID: 1, Image: "image", Priority: 0.5, Revision: 3},
+		},
+		{
+			name:  "Stale revision",
+			patch: &models.ClientPatch{ID: 1, Priority: &priority, Revision: 1},
+			mockBehavior: func(s *mock_storage.MockStorage, patch *models.ClientPatch) {
+				s.EXPECT().PatchClient(gomock.Any(), patch).Return(nil, storage.ErrStaleRevision)
+			},
+			expectedError: storage.ErrStaleRevision,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := mock_storage.NewMockStorage(ctrl)
+			namespaces := mock_storage.NewMockNamespaces(ctrl)
+			tc.mockBehavior(storage, tc.patch)
+
+			log := slogdiscard.NewDiscardLogger()
+			service := New(storage, namespaces, log)
+
+			// Test method
+			client, err := service.PatchClient(context.Background(), tc.patch)
+
+			// Assert results
+			assert.Equal(t, tc.expectedClient, client)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestService_GetAlgorithms(t *testing.T) {
+	type mockBehavior func(s *mock_storage.MockStorage, clientID int)
+
+	tt := []struct {
+		name             string
+		clientID         int
+		mockBehavior     mockBehavior
+		expectedStatuses *models.AlgoStatuses
+		expectedError    error
+	}{
+		{
+			name:     "Successful statuses fetching",
+			clientID: 1,
+			mockBehavior: func(s *mock_storage.MockStorage, clientID int) {
+				s.EXPECT().FetchStatuses(gomock.Any(), clientID).Return(&models.AlgoStatuses{
+					ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": false},
+				}, nil)
+			},
+			expectedStatuses: &models.AlgoStatuses{ClientID: 1, Algorithms: map[string]bool{"vwap": true, "twap": false, "hft": false}},
+		},
+		{
+			name:     "Storage error",
+			clientID: 1,
+			mockBehavior: func(s *mock_storage.MockStorage, clientID int) {
+				s.EXPECT().FetchStatuses(gomock.Any(), clientID).Return(nil, errors.New("internal storage error"))
+			},
+			expectedError: errors.New("internal storage error"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := mock_storage.NewMockStorage(ctrl)
+			namespaces := mock_storage.NewMockNamespaces(ctrl)
+			tc.mockBehavior(storage, tc.clientID)
+
+			log := slogdiscard.NewDiscardLogger()
+			service := New(storage, namespaces, log)
+
+			// Test method
+			statuses, err := service.GetAlgorithms(context.Background(), tc.clientID)
+
+			// Assert results
+			assert.Equal(t, tc.expectedStatuses, statuses)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
+
+func TestService_ApplyBatch(t *testing.T) {
+	type mockBehavior func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, batch *models.ClientBatch)
+
+	tt := []struct {
+		name            string
+		batch           models.ClientBatch
+		mockBehavior    mockBehavior
+		expectedClients []*models.Client
+		expectedErrs    []error
+		expectedError   error
+	}{
+		{
+			name: "Create namespaces of created clients",
+			batch: models.ClientBatch{Items: []models.ClientBatchItem{
+				{Op: models.BatchCreate, Client: models.Client{ClientName: "clientA"}},
+				{Op: models.BatchUpdate, Client: models.Client{ID: 2, ClientName: "clientB"}},
+				{Op: models.BatchCreate, Client: models.Client{ClientName: "clientC"}},
+			}},
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, batch *models.ClientBatch) {
+				s.EXPECT().ApplyClientBatch(gomock.Any(), batch.Items, false).Return(
+					[]*models.Client{{ID: 1}, {ID: 2}, nil},
+					[]error{nil, nil, errors.New("internal storage error")},
+					nil,
+				)
+				n.EXPECT().CreateNamespace(gomock.Any(), 1).Return(nil)
+			},
+			expectedClients: []*models.Client{{ID: 1}, {ID: 2}, nil},
+			expectedErrs:    []error{nil, nil, errors.New("internal storage error")},
+		},
+		{
+			name: "Namespace error",
+			batch: models.ClientBatch{Items: []models.ClientBatchItem{
+				{Op: models.BatchCreate, Client: models.Client{ClientName: "clientA"}},
+			}},
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, batch *models.ClientBatch) {
+				s.EXPECT().ApplyClientBatch(gomock.Any(), batch.Items, false).Return([]*models.Client{{ID: 1}}, []error{nil}, nil)
+				n.EXPECT().CreateNamespace(gomock.Any(), 1).Return(errors.New("kubernetes error"))
+			},
+			expectedClients: []*models.Client{{ID: 1}},
+			expectedErrs:    []error{nil},
+		},
+		{
+			name: "Atomic batch aborted",
+			batch: models.ClientBatch{Atomic: true, Items: []models.ClientBatchItem{
+				{Op: models.BatchCreate, Client: models.Client{ClientName: "clientA"}},
+				{Op: models.BatchUpdate, Client: models.Client{ID: 5, ClientName: "clientB"}},
+			}},
+			mockBehavior: func(s *mock_storage.MockStorage, n *mock_storage.MockNamespaces, batch *models.ClientBatch) {
+				s.EXPECT().ApplyClientBatch(gomock.Any(), batch.Items, true).Return(
+					[]*models.Client{{ID: 1}, nil},
+					[]error{nil, errors.New("client not found")},
+					errors.New("batch rolled back"),
+				)
+			},
+			expectedClients: []*models.Client{{ID: 1}, nil},
+			expectedErrs:    []error{nil, errors.New("client not found")},
+			expectedError:   errors.New("batch rolled back"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			// Init deps
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storage := mock_storage.NewMockStorage(ctrl)
+			namespaces := mock_storage.NewMockNamespaces(ctrl)
+			tc.mockBehavior(storage, namespaces, &tc.batch)
+
+			log := slogdiscard.NewDiscardLogger()
+			service := New(storage, namespaces, log)
+
+			// Test method
+			clients, errs, err := service.ApplyBatch(context.Background(), &tc.batch)
+
+			// Assert results
+			assert.Equal(t, tc.expectedClients, clients)
+			assert.Equal(t, tc.expectedErrs, errs)
+			assert.Equal(t, tc.expectedError, err)
+		})
+	}
+}
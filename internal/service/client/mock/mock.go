@@ -35,6 +35,22 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// ApplyClientBatch mocks base method.
+func (m *MockStorage) ApplyClientBatch(ctx context.Context, items []models.ClientBatchItem, atomic bool) ([]*models.Client, []error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyClientBatch", ctx, items, atomic)
+	ret0, _ := ret[0].([]*models.Client)
+	ret1, _ := ret[1].([]error)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ApplyClientBatch indicates an expected call of ApplyClientBatch.
+func (mr *MockStorageMockRecorder) ApplyClientBatch(ctx, items, atomic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyClientBatch", reflect.TypeOf((*MockStorage)(nil).ApplyClientBatch), ctx, items, atomic)
+}
+
 // CreateClient mocks base method.
 func (m *MockStorage) CreateClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +66,81 @@ func (mr *MockStorageMockRecorder) CreateClient(ctx, clientInfo interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockStorage)(nil).CreateClient), ctx, clientInfo)
 }
 
+// FetchClient mocks base method.
+func (m *MockStorage) FetchClient(ctx context.Context, id int) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchClient", ctx, id)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchClient indicates an expected call of FetchClient.
+func (mr *MockStorageMockRecorder) FetchClient(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchClient", reflect.TypeOf((*MockStorage)(nil).FetchClient), ctx, id)
+}
+
+// FetchClientPage mocks base method.
+func (m *MockStorage) FetchClientPage(ctx context.Context, query *models.ClientQuery) (*models.ClientPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchClientPage", ctx, query)
+	ret0, _ := ret[0].(*models.ClientPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchClientPage indicates an expected call of FetchClientPage.
+func (mr *MockStorageMockRecorder) FetchClientPage(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchClientPage", reflect.TypeOf((*MockStorage)(nil).FetchClientPage), ctx, query)
+}
+
+// FetchParams mocks base method.
+func (m *MockStorage) FetchParams(ctx context.Context, clientID int, algorithm string) (*models.AlgoParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchParams", ctx, clientID, algorithm)
+	ret0, _ := ret[0].(*models.AlgoParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchParams indicates an expected call of FetchParams.
+func (mr *MockStorageMockRecorder) FetchParams(ctx, clientID, algorithm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchParams", reflect.TypeOf((*MockStorage)(nil).FetchParams), ctx, clientID, algorithm)
+}
+
+// FetchStatuses mocks base method.
+func (m *MockStorage) FetchStatuses(ctx context.Context, clientID int) (*models.AlgoStatuses, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchStatuses", ctx, clientID)
+	ret0, _ := ret[0].(*models.AlgoStatuses)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchStatuses indicates an expected call of FetchStatuses.
+func (mr *MockStorageMockRecorder) FetchStatuses(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchStatuses", reflect.TypeOf((*MockStorage)(nil).FetchStatuses), ctx, clientID)
+}
+
+// PatchClient mocks base method.
+func (m *MockStorage) PatchClient(ctx context.Context, patch *models.ClientPatch) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchClient", ctx, patch)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchClient indicates an expected call of PatchClient.
+func (mr *MockStorageMockRecorder) PatchClient(ctx, patch interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchClient", reflect.TypeOf((*MockStorage)(nil).PatchClient), ctx, patch)
+}
+
 // RemoveClient mocks base method.
 func (m *MockStorage) RemoveClient(ctx context.Context, id int) error {
 	m.ctrl.T.Helper()
@@ -65,11 +156,12 @@ func (mr *MockStorageMockRecorder) RemoveClient(ctx, id interface{}) *gomock.Cal
 }
 
 // UpdateClient mocks base method.
-func (m *MockStorage) UpdateClient(ctx context.Context, clientInfo *models.Client) error {
+func (m *MockStorage) UpdateClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateClient", ctx, clientInfo)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdateClient indicates an expected call of UpdateClient.
@@ -77,3 +169,69 @@ func (mr *MockStorageMockRecorder) UpdateClient(ctx, clientInfo interface{}) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClient", reflect.TypeOf((*MockStorage)(nil).UpdateClient), ctx, clientInfo)
 }
+
+// UpdateParams mocks base method.
+func (m *MockStorage) UpdateParams(ctx context.Context, algoParams *models.AlgoParams) (*models.AlgoParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateParams", ctx, algoParams)
+	ret0, _ := ret[0].(*models.AlgoParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateParams indicates an expected call of UpdateParams.
+func (mr *MockStorageMockRecorder) UpdateParams(ctx, algoParams interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateParams", reflect.TypeOf((*MockStorage)(nil).UpdateParams), ctx, algoParams)
+}
+
+// MockNamespaces is a mock of Namespaces interface.
+type MockNamespaces struct {
+	ctrl     *gomock.Controller
+	recorder *MockNamespacesMockRecorder
+}
+
+// MockNamespacesMockRecorder is the mock recorder for MockNamespaces.
+type MockNamespacesMockRecorder struct {
+	mock *MockNamespaces
+}
+
+// NewMockNamespaces creates a new mock instance.
+func NewMockNamespaces(ctrl *gomock.Controller) *MockNamespaces {
+	mock := &MockNamespaces{ctrl: ctrl}
+	mock.recorder = &MockNamespacesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNamespaces) EXPECT() *MockNamespacesMockRecorder {
+	return m.recorder
+}
+
+// CreateNamespace mocks base method.
+func (m *MockNamespaces) CreateNamespace(ctx context.Context, clientID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNamespace", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateNamespace indicates an expected call of CreateNamespace.
+func (mr *MockNamespacesMockRecorder) CreateNamespace(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNamespace", reflect.TypeOf((*MockNamespaces)(nil).CreateNamespace), ctx, clientID)
+}
+
+// DeleteNamespace mocks base method.
+func (m *MockNamespaces) DeleteNamespace(ctx context.Context, clientID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNamespace", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNamespace indicates an expected call of DeleteNamespace.
+func (mr *MockNamespacesMockRecorder) DeleteNamespace(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNamespace", reflect.TypeOf((*MockNamespaces)(nil).DeleteNamespace), ctx, clientID)
+}
@@ -0,0 +1,399 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UpdateStatuses enables and disables the client's algorithms, leaving the ones missing in the statuses as they are
+func (s *Storage) UpdateStatuses(ctx context.Context, algoStatuses *models.AlgoStatuses) (*models.AlgoStatuses, error) {
+	const op = "storage.postgres.UpdateStatuses"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
+
+	statuses, err := updateStatuses(ctx, tx, algoStatuses)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return statuses, nil
+}
+
+// FetchCurrentStatuses returns the statuses of all clients having any algorithm set
+func (s *Storage) FetchCurrentStatuses(ctx context.Context) ([]models.AlgoStatuses, error) {
+	const op = "storage.postgres.FetchCurrentStatuses"
+
+	rows, err := s.pool.Query(ctx, `SELECT client_id, algorithm, enabled FROM client_algorithms ORDER BY client_id`)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	defer rows.Close()
+
+	var statuses []models.AlgoStatuses
+	for rows.Next() {
+		var (
+			clientID  int
+			algorithm string
+			enabled   bool
+		)
+		if err := rows.Scan(&clientID, &algorithm, &enabled); err != nil {
+			return nil, wrap(op, err)
+		}
+
+		// Rows are ordered by client
+		if len(statuses) == 0 || statuses[len(statuses)-1].ClientID != clientID {
+			statuses = append(statuses, models.AlgoStatuses{ClientID: clientID, Algorithms: map[string]bool{}})
+		}
+		statuses[len(statuses)-1].Algorithms[algorithm] = enabled
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return statuses, nil
+}
+
+// FetchStatuses returns the statuses of every catalog algorithm for the client along with the observed states of its workloads
+func (s *Storage) FetchStatuses(ctx context.Context, clientID int) (*models.AlgoStatuses, error) {
+	const op = "storage.postgres.FetchStatuses"
+
+	statuses, err := fetchStatuses(ctx, s.pool, clientID)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	statuses.Workloads, err = fetchWorkloadStates(ctx, s.pool, clientID)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return statuses, nil
+}
+
+// FetchAlgorithms returns the algorithm catalog
+func (s *Storage) FetchAlgorithms(ctx context.Context) ([]models.Algorithm, error) {
+	const op = "storage.postgres.FetchAlgorithms"
+
+	rows, err := s.pool.Query(ctx, `SELECT name, image, cpu, memory, enabled FROM algorithms ORDER BY name`)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	defer rows.Close()
+
+	var algorithms []models.Algorithm
+	for rows.Next() {
+		var algorithm models.Algorithm
+		if err := rows.Scan(&algorithm.Name, &algorithm.Image, &algorithm.CPU, &algorithm.Memory, &algorithm.Enabled); err != nil {
+			return nil, wrap(op, err)
+		}
+		algorithms = append(algorithms, algorithm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return algorithms, nil
+}
+
+// SaveAlgorithm adds the algorithm to the catalog or replaces its defaults,
+// the unset enabled flag keeps the stored one, a new algorithm is enabled
+func (s *Storage) SaveAlgorithm(ctx context.Context, algorithm *models.Algorithm) (*models.Algorithm, error) {
+	const op = "storage.postgres.SaveAlgorithm"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
+
+	// The event of a new algorithm has no state before the change
+	var before any
+	var previous models.Algorithm
+	err = tx.QueryRow(ctx, `SELECT name, image, cpu, memory, enabled FROM algorithms WHERE name = $1 FOR UPDATE`, algorithm.Name).
+		Scan(&previous.Name, &previous.Image, &previous.CPU, &previous.Memory, &previous.Enabled)
+	switch {
+	case err == nil:
+		before = &previous
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, wrap(op, err)
+	}
+
+	row := tx.QueryRow(ctx, `
+		INSERT INTO algorithms (name, image, cpu, memory, enabled)
+		VALUES ($1, $2, $3, $4, COALESCE($5, TRUE))
+		ON CONFLICT (name) DO UPDATE
+		SET image = EXCLUDED.image, cpu = EXCLUDED.cpu, memory = EXCLUDED.memory,
+			enabled = COALESCE($5, algorithms.enabled)
+		RETURNING name, image, cpu, memory, enabled
+	`, algorithm.Name, algorithm.Image, algorithm.CPU, algorithm.Memory, algorithm.Enabled)
+
+	var saved models.Algorithm
+	err = row.Scan(&saved.Name, &saved.Image, &saved.CPU, &saved.Memory, &saved.Enabled)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	err = recordEvent(ctx, tx, models.AuditCatalogSave, 0, before, &saved)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return &saved, nil
+}
+
+// updateStatuses sets the client's algorithms validated against the catalog within the transaction
+// and returns all of its statuses
+func updateStatuses(ctx context.Context, q querier, algoStatuses *models.AlgoStatuses) (*models.AlgoStatuses, error) {
+	// The client is locked, so the statuses recorded as the ones before the change are not changed concurrently
+	if _, err := lockClient(ctx, q, int64(algoStatuses.ClientID), anyRevision); err != nil {
+		return nil, err
+	}
+
+	before, err := fetchStatuses(ctx, q, algoStatuses.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, enabled := range algoStatuses.Algorithms {
+		ct, err := q.Exec(ctx, `
+			INSERT INTO client_algorithms (client_id, algorithm, enabled)
+			SELECT $1, name, $3 FROM algorithms WHERE name = $2
+			ON CONFLICT (client_id, algorithm) DO UPDATE SET enabled = EXCLUDED.enabled
+		`, algoStatuses.ClientID, name, enabled)
+		if err != nil {
+			return nil, err
+		}
+
+		if ct.RowsAffected() == 0 {
+			return nil, fmt.Errorf("%w: %q", storage.ErrUnknownAlgorithm, name)
+		}
+	}
+
+	after, err := fetchStatuses(ctx, q, algoStatuses.ClientID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordEvent(ctx, q, models.AuditAlgorithmsToggle, int64(algoStatuses.ClientID), before, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// fetchStatuses returns the statuses of every catalog algorithm for the client
+func fetchStatuses(ctx context.Context, q querier, clientID int) (*models.AlgoStatuses, error) {
+	var exists bool
+	err := q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM clients WHERE id = $1)`, clientID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, storage.ErrUserNotFound
+	}
+
+	rows, err := q.Query(ctx, `
+		SELECT a.name, COALESCE(ca.enabled, FALSE)
+		FROM algorithms a
+		LEFT JOIN client_algorithms ca ON ca.algorithm = a.name AND ca.client_id = $1
+	`, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	statuses := models.AlgoStatuses{ClientID: clientID, Algorithms: map[string]bool{}}
+	for rows.Next() {
+		var (
+			name    string
+			enabled bool
+		)
+		if err := rows.Scan(&name, &enabled); err != nil {
+			return nil, err
+		}
+		statuses.Algorithms[name] = enabled
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &statuses, nil
+}
+
+// fetchWorkloadStates returns the observed states of the client's workloads recorded by the scheduler
+func fetchWorkloadStates(ctx context.Context, q querier, clientID int) (map[string]models.WorkloadState, error) {
+	rows, err := q.Query(ctx, `
+		SELECT algorithm, pod_name, phase, reason, restart_count, image, version, last_error, last_transition_at, updated_at
+		FROM workloads
+		WHERE client_id = $1
+	`, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := map[string]models.WorkloadState{}
+	for rows.Next() {
+		var (
+			algorithm string
+			state     models.WorkloadState
+		)
+		err := rows.Scan(
+			&algorithm,
+			&state.Pod,
+			&state.Phase,
+			&state.Reason,
+			&state.RestartCount,
+			&state.Image,
+			&state.Version,
+			&state.LastError,
+			&state.LastTransitionAt,
+			&state.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		states[algorithm] = state
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+// FetchCurrentParams returns the parameters set for the clients' algorithms, skipping the empty ones
+func (s *Storage) FetchCurrentParams(ctx context.Context) ([]models.AlgoParams, error) {
+	const op = "storage.postgres.FetchCurrentParams"
+
+	rows, err := s.pool.Query(ctx, `SELECT client_id, algorithm, params FROM client_algorithms WHERE params <> '{}'`)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	defer rows.Close()
+
+	var params []models.AlgoParams
+	for rows.Next() {
+		var p models.AlgoParams
+		if err := rows.Scan(&p.ClientID, &p.Algorithm, &p.Params); err != nil {
+			return nil, wrap(op, err)
+		}
+		params = append(params, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return params, nil
+}
+
+// FetchParams returns the parameters of the client's algorithm, an empty object if none are set
+func (s *Storage) FetchParams(ctx context.Context, clientID int, algorithm string) (*models.AlgoParams, error) {
+	const op = "storage.postgres.FetchParams"
+
+	row := s.pool.QueryRow(ctx, `
+		SELECT
+			EXISTS (SELECT 1 FROM clients WHERE id = $1),
+			EXISTS (SELECT 1 FROM algorithms WHERE name = $2),
+			COALESCE((SELECT params FROM client_algorithms WHERE client_id = $1 AND algorithm = $2), '{}')
+	`, clientID, algorithm)
+
+	var (
+		clientExists, algorithmExists bool
+		params                        = models.AlgoParams{ClientID: clientID, Algorithm: algorithm}
+	)
+	err := row.Scan(&clientExists, &algorithmExists, &params.Params)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	if !clientExists {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+	if !algorithmExists {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrAlgorithmNotFound)
+	}
+
+	return &params, nil
+}
+
+// UpdateParams replaces the parameters of the client's algorithm, keeping whether it is enabled
+func (s *Storage) UpdateParams(ctx context.Context, algoParams *models.AlgoParams) (*models.AlgoParams, error) {
+	const op = "storage.postgres.UpdateParams"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
+
+	if _, err := lockClient(ctx, tx, int64(algoParams.ClientID), anyRevision); err != nil {
+		return nil, wrap(op, err)
+	}
+
+	before := models.AlgoParams{ClientID: algoParams.ClientID, Algorithm: algoParams.Algorithm}
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE((SELECT params FROM client_algorithms WHERE client_id = $1 AND algorithm = $2), '{}')
+	`, algoParams.ClientID, algoParams.Algorithm).Scan(&before.Params)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	row := tx.QueryRow(ctx, `
+		INSERT INTO client_algorithms (client_id, algorithm, params)
+		SELECT $1, name, $3 FROM algorithms WHERE name = $2
+		ON CONFLICT (client_id, algorithm) DO UPDATE SET params = EXCLUDED.params
+		RETURNING params
+	`, algoParams.ClientID, algoParams.Algorithm, algoParams.Params)
+
+	updated := models.AlgoParams{ClientID: algoParams.ClientID, Algorithm: algoParams.Algorithm}
+	err = row.Scan(&updated.Params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrAlgorithmNotFound)
+	}
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	err = recordEvent(ctx, tx, models.AuditParamsUpdate, int64(algoParams.ClientID), &before, &updated)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return &updated, nil
+}
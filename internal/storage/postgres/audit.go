@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"sync-algo/internal/lib/audit"
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+)
+
+// FetchAuditEvents returns a page of the audit events matching the query, the newest first
+func (s *Storage) FetchAuditEvents(ctx context.Context, query *models.AuditQuery) (*models.AuditPage, error) {
+	const op = "storage.postgres.FetchAuditEvents"
+
+	where := &conditions{}
+	if query.ClientID != 0 {
+		where.add("client_id = " + where.arg(query.ClientID))
+	}
+	if query.From != nil {
+		where.add("created_at >= " + where.arg(*query.From))
+	}
+	if query.To != nil {
+		where.add("created_at <= " + where.arg(*query.To))
+	}
+
+	// The events are ordered by id, which grows along with the time of the events
+	if query.Cursor != "" {
+		before, err := decodeEventCursor(query.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrInvalidCursor)
+		}
+		where.add("id < " + where.arg(before))
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, actor, request_id, action, COALESCE(client_id, 0), before, after, created_at
+		FROM audit_events`+where.String()+
+		fmt.Sprintf(` ORDER BY id DESC LIMIT %d`, query.Limit+1),
+		where.args...,
+	)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	defer rows.Close()
+
+	page := &models.AuditPage{Events: make([]models.AuditEvent, 0, query.Limit)}
+	for rows.Next() {
+		var event models.AuditEvent
+		err := rows.Scan(
+			&event.ID,
+			&event.Actor,
+			&event.RequestID,
+			&event.Action,
+			&event.ClientID,
+			&event.Before,
+			&event.After,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, wrap(op, err)
+		}
+		page.Events = append(page.Events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrap(op, err)
+	}
+
+	// The extra event fetched tells there is a next page
+	if len(page.Events) > query.Limit {
+		page.Events = page.Events[:query.Limit]
+		page.NextCursor = encodeEventCursor(page.Events[len(page.Events)-1].ID)
+	}
+
+	return page, nil
+}
+
+// recordEvent appends the audit event of the change made within the transaction, so the event is committed
+// or rolled back along with the change. The states are stored as JSON, the nil ones as NULL
+func recordEvent(ctx context.Context, q querier, action string, clientID int64, before, after any) error {
+	actor := audit.ActorFrom(ctx)
+
+	beforeJSON, err := marshalState(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := marshalState(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(ctx, `
+		INSERT INTO audit_events (actor, request_id, action, client_id, before, after)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6)
+	`, actor.Subject, actor.RequestID, action, clientID, beforeJSON, afterJSON)
+
+	return err
+}
+
+func marshalState(state any) ([]byte, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(state)
+}
+
+// encodeEventCursor renders the id of the last event of the page opaque to the API clients
+func encodeEventCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeEventCursor(cursor string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseInt(string(raw), 10, 64)
+}
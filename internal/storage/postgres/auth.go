@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// FetchAPIKey returns the API key which isn't revoked by the hex-encoded SHA-256 of the key
+func (s *Storage) FetchAPIKey(ctx context.Context, hash string) (*models.APIKey, error) {
+	const op = "storage.postgres.FetchAPIKey"
+
+	var key models.APIKey
+	err := s.pool.QueryRow(ctx, `
+		SELECT id, name, created_at
+		FROM api_keys
+		WHERE key_hash = $1 AND revoked_at IS NULL`,
+		hash,
+	).Scan(&key.ID, &key.Name, &key.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrAPIKeyNotFound)
+	}
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return &key, nil
+}
+
+// FetchRole returns the role assigned to the authenticated caller
+func (s *Storage) FetchRole(ctx context.Context, subject string) (string, error) {
+	const op = "storage.postgres.FetchRole"
+
+	var role string
+	err := s.pool.QueryRow(ctx, `SELECT role FROM role_assignments WHERE subject = $1`, subject).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("%s: %w", op, storage.ErrRoleNotAssigned)
+	}
+	if err != nil {
+		return "", wrap(op, err)
+	}
+
+	return role, nil
+}
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ApplyClientBatch creates and updates the clients in a single transaction.
+// It returns the resulting client and the error of every item.
+func (s *Storage) ApplyClientBatch(ctx context.Context, items []models.ClientBatchItem, atomic bool) ([]*models.Client, []error, error) {
+	const op = "storage.postgres.ApplyClientBatch"
+
+	clients := make([]*models.Client, len(items))
+
+	errs, err := s.applyBatch(ctx, len(items), atomic, func(tx pgx.Tx, i int) error {
+		var err error
+
+		switch items[i].Op {
+		case models.BatchCreate:
+			clients[i], err = insertClient(ctx, tx, &items[i].Client)
+		case models.BatchUpdate:
+			clients[i], err = updateClient(ctx, tx, &items[i].Client)
+		default:
+			err = fmt.Errorf("unknown operation %q", items[i].Op)
+		}
+
+		return err
+	})
+	if err != nil {
+		return clients, errs, wrap(op, err)
+	}
+
+	return clients, errs, nil
+}
+
+// ApplyStatusesBatch updates the algorithm statuses of many clients in a single transaction,
+// leaving the algorithms missing in the item as they are.
+// It returns the resulting statuses and the error of every item.
+func (s *Storage) ApplyStatusesBatch(ctx context.Context, items []models.AlgoStatuses, atomic bool) ([]*models.AlgoStatuses, []error, error) {
+	const op = "storage.postgres.ApplyStatusesBatch"
+
+	statuses := make([]*models.AlgoStatuses, len(items))
+
+	errs, err := s.applyBatch(ctx, len(items), atomic, func(tx pgx.Tx, i int) error {
+		var err error
+		statuses[i], err = updateStatuses(ctx, tx, &items[i])
+		return err
+	})
+	if err != nil {
+		return statuses, errs, wrap(op, err)
+	}
+
+	return statuses, errs, nil
+}
+
+// applyBatch calls apply for every item in a single transaction, so the scheduler is notified
+// about the whole batch once. Every item runs in its own savepoint, so a failed item is rolled back alone,
+// unless the batch is atomic: then the whole transaction is rolled back and ErrBatchAborted is returned.
+func (s *Storage) applyBatch(ctx context.Context, n int, atomic bool, apply func(tx pgx.Tx, i int) error) ([]error, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
+
+	errs := make([]error, n)
+
+	for i := 0; i < n; i++ {
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if errs[i] = apply(savepoint, i); errs[i] != nil {
+			if atomic {
+				return errs, storage.ErrBatchAborted
+			}
+
+			if err := savepoint.Rollback(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,256 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"sync-algo/internal/models"
+	"sync-algo/internal/storage"
+)
+
+// sortKey is an expression over the clients aliased as c the clients are ordered by,
+// and the way a cursor's value is compared with it
+type sortKey struct {
+	expr string
+	// value wraps the placeholder of the cursor's value, so it compares as the expression does
+	value string
+}
+
+// sortKeys maps the models.ClientSort keys to the expressions, indexed along with the id by the migrations
+var sortKeys = map[string]sortKey{
+	models.ClientSortID:        {expr: "c.id", value: "%s"},
+	models.ClientSortName:      {expr: "lower(c.name)", value: "lower(%s)"},
+	models.ClientSortCreatedAt: {expr: "c.created_at", value: "%s"},
+	models.ClientSortPriority:  {expr: "c.priority", value: "%s"},
+}
+
+// likeEscaper escapes the wildcards of the name searched as a substring
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// cursor is the position after the last client of a page, only the sort key's value is set
+type cursor struct {
+	Sort      string     `json:"s"`
+	Desc      bool       `json:"d,omitempty"`
+	ID        int64      `json:"id"`
+	Name      string     `json:"n,omitempty"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	Priority  float64    `json:"p,omitempty"`
+}
+
+// FetchClientPage returns a page of the clients matching the query, along with the number of the matching clients
+func (s *Storage) FetchClientPage(ctx context.Context, query *models.ClientQuery) (*models.ClientPage, error) {
+	const op = "storage.postgres.FetchClientPage"
+
+	sortBy := query.Sort
+	if sortBy == "" {
+		sortBy = models.ClientSortID
+	}
+	if _, ok := sortKeys[sortBy]; !ok {
+		return nil, fmt.Errorf("%s: unknown sort key %q", op, sortBy)
+	}
+
+	var after *cursor
+	if query.Cursor != "" {
+		var err error
+		after, err = decodeCursor(query.Cursor)
+		if err != nil || after.Sort != sortBy || after.Desc != query.Desc {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrInvalidCursor)
+		}
+	}
+
+	where := clientFilters(query)
+
+	var total int
+	err := s.pool.QueryRow(ctx, `SELECT count(*) FROM clients c`+where.String(), where.args...).Scan(&total)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	sql, args := clientPageSQL(query, sortBy, after)
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	defer rows.Close()
+
+	page := &models.ClientPage{
+		Clients: make([]models.Client, 0, query.Limit),
+		Total:   total,
+	}
+	for rows.Next() {
+		var client models.Client
+		if err := scanClient(rows, &client); err != nil {
+			return nil, wrap(op, err)
+		}
+		page.Clients = append(page.Clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrap(op, err)
+	}
+
+	// The extra client fetched tells there is a next page
+	if len(page.Clients) > query.Limit {
+		page.Clients = page.Clients[:query.Limit]
+
+		last := page.Clients[len(page.Clients)-1]
+		page.NextCursor, err = encodeCursor(newCursor(sortBy, query.Desc, &last))
+		if err != nil {
+			return nil, wrap(op, err)
+		}
+	}
+
+	return page, nil
+}
+
+// clientPageSQL builds the query of the page's clients following the cursor, if any.
+// One client more than the limit is fetched to tell whether there is a next page
+func clientPageSQL(query *models.ClientQuery, sortBy string, after *cursor) (string, []any) {
+	key := sortKeys[sortBy]
+	where := clientFilters(query)
+
+	order, direction := "ASC", ">"
+	if query.Desc {
+		order, direction = "DESC", "<"
+	}
+
+	// The keyset condition skips the clients up to the cursor, the ties of the sort key are ordered by the id
+	if after != nil {
+		if sortBy == models.ClientSortID {
+			where.add("c.id " + direction + " " + where.arg(after.ID))
+		} else {
+			value := fmt.Sprintf(key.value, where.arg(after.value()))
+			where.add(fmt.Sprintf("(%s, c.id) %s (%s, %s)", key.expr, direction, value, where.arg(after.ID)))
+		}
+	}
+
+	orderBy := key.expr + " " + order
+	if sortBy != models.ClientSortID {
+		orderBy += ", c.id " + order
+	}
+
+	sql := `SELECT ` + clientColumns + ` FROM clients c` + where.String() +
+		fmt.Sprintf(` ORDER BY %s LIMIT %d`, orderBy, query.Limit+1)
+
+	return sql, where.args
+}
+
+// conditions collects the WHERE clauses along with their arguments
+type conditions struct {
+	clauses []string
+	args    []any
+}
+
+// arg adds the argument, returning its placeholder
+func (c *conditions) arg(value any) string {
+	c.args = append(c.args, value)
+	return "$" + strconv.Itoa(len(c.args))
+}
+
+func (c *conditions) add(clause string) {
+	c.clauses = append(c.clauses, clause)
+}
+
+// String renders the WHERE clause, empty if there are no conditions
+func (c *conditions) String() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// clientFilters translates the query's filters into the conditions on the clients aliased as c
+func clientFilters(query *models.ClientQuery) *conditions {
+	where := &conditions{}
+
+	if query.Name != "" {
+		where.add("c.name ILIKE " + where.arg("%"+likeEscaper.Replace(query.Name)+"%"))
+	}
+	if query.Algorithm != "" {
+		where.add(`EXISTS (
+			SELECT 1 FROM client_algorithms ca
+			WHERE ca.client_id = c.id AND ca.algorithm = ` + where.arg(query.Algorithm) + ` AND ca.enabled
+		)`)
+	}
+	if query.NeedRestart != nil {
+		where.add("c.need_restart = " + where.arg(*query.NeedRestart))
+	}
+	if query.CreatedFrom != nil {
+		where.add("c.created_at >= " + where.arg(*query.CreatedFrom))
+	}
+	if query.CreatedTo != nil {
+		where.add("c.created_at <= " + where.arg(*query.CreatedTo))
+	}
+	if query.PriorityMin != nil {
+		where.add("c.priority >= " + where.arg(*query.PriorityMin))
+	}
+	if query.PriorityMax != nil {
+		where.add("c.priority <= " + where.arg(*query.PriorityMax))
+	}
+
+	return where
+}
+
+// newCursor creates the cursor positioned after the client
+func newCursor(sortBy string, desc bool, client *models.Client) *cursor {
+	c := &cursor{Sort: sortBy, Desc: desc, ID: client.ID}
+
+	switch sortBy {
+	case models.ClientSortName:
+		c.Name = client.ClientName
+	case models.ClientSortCreatedAt:
+		c.CreatedAt = &client.CreatedAt
+	case models.ClientSortPriority:
+		c.Priority = client.Priority
+	}
+
+	return c
+}
+
+// value returns the cursor's value of its sort key
+func (c *cursor) value() any {
+	switch c.Sort {
+	case models.ClientSortName:
+		return c.Name
+	case models.ClientSortCreatedAt:
+		if c.CreatedAt == nil {
+			return time.Time{}
+		}
+		return *c.CreatedAt
+	case models.ClientSortPriority:
+		return c.Priority
+	default:
+		return c.ID
+	}
+}
+
+// encodeCursor renders the cursor opaque to the API clients
+func encodeCursor(c *cursor) (string, error) {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
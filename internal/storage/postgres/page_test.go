@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"sync-algo/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientPageSQL(t *testing.T) {
+	const selectClients = `SELECT ` + clientColumns + ` FROM clients c`
+
+	createdAt := time.Date(2024, 7, 17, 14, 30, 0, 0, time.UTC)
+
+	tt := []struct {
+		name         string
+		query        models.ClientQuery
+		after        *cursor
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			name:        "First page by id",
+			query:       models.ClientQuery{Sort: models.ClientSortID, Limit: 50},
+			expectedSQL: selectClients + ` ORDER BY c.id ASC LIMIT 51`,
+		},
+		{
+			name:         "Next page by id",
+			query:        models.ClientQuery{Sort: models.ClientSortID, Limit: 50},
+			after:        &cursor{Sort: models.ClientSortID, ID: 50},
+			expectedSQL:  selectClients + ` WHERE c.id > $1 ORDER BY c.id ASC LIMIT 51`,
+			expectedArgs: []any{int64(50)},
+		},
+		{
+			name:        "First page by name",
+			query:       models.ClientQuery{Sort: models.ClientSortName, Limit: 10},
+			expectedSQL: selectClients + ` ORDER BY lower(c.name) ASC, c.id ASC LIMIT 11`,
+		},
+		{
+			name:         "Next page by name",
+			query:        models.ClientQuery{Sort: models.ClientSortName, Limit: 10},
+			after:        &cursor{Sort: models.ClientSortName, ID: 7, Name: "Desk"},
+			expectedSQL:  selectClients + ` WHERE (lower(c.name), c.id) > (lower($1), $2) ORDER BY lower(c.name) ASC, c.id ASC LIMIT 11`,
+			expectedArgs: []any{"Desk", int64(7)},
+		},
+		{
+			name:         "Next page by creation time, descending",
+			query:        models.ClientQuery{Sort: models.ClientSortCreatedAt, Desc: true, Limit: 10},
+			after:        &cursor{Sort: models.ClientSortCreatedAt, Desc: true, ID: 7, CreatedAt: &createdAt},
+			expectedSQL:  selectClients + ` WHERE (c.created_at, c.id) < ($1, $2) ORDER BY c.created_at DESC, c.id DESC LIMIT 11`,
+			expectedArgs: []any{createdAt, int64(7)},
+		},
+		{
+			name:         "First page by id of clients waiting for restart",
+			query:        models.ClientQuery{NeedRestart: boolPtr(true), Sort: models.ClientSortID, Desc: true, Limit: 10},
+			expectedSQL:  selectClients + ` WHERE c.need_restart = $1 ORDER BY c.id DESC LIMIT 11`,
+			expectedArgs: []any{true},
+		},
+		{
+			name:         "Next page by priority with filters",
+			query:        models.ClientQuery{Name: "desk_%", Sort: models.ClientSortPriority, Limit: 10},
+			after:        &cursor{Sort: models.ClientSortPriority, ID: 7, Priority: 0.5},
+			expectedSQL:  selectClients + ` WHERE c.name ILIKE $1 AND (c.priority, c.id) > ($2, $3) ORDER BY c.priority ASC, c.id ASC LIMIT 11`,
+			expectedArgs: []any{`%desk\_\%%`, 0.5, int64(7)},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args := clientPageSQL(&tc.query, tc.query.Sort, tc.after)
+
+			assert.Equal(t, tc.expectedSQL, sql)
+			assert.Equal(t, tc.expectedArgs, args)
+		})
+	}
+}
+
+func TestSortKeys(t *testing.T) {
+	// The expressions are qualified by the alias inside, c.lower(name) would be read as a function of schema c
+	for sortBy, key := range sortKeys {
+		assert.Contains(t, key.expr, "c.", sortBy)
+		assert.NotRegexp(t, `^c\.\w+\(`, key.expr, sortBy)
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -2,8 +2,8 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"sync-algo/internal/config"
@@ -13,10 +13,27 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 )
 
+// clientColumns lists the columns of the clients table in the order scanClient reads them
+const clientColumns = `id, name, version, image, cpu, memory, priority, need_restart, restart_error, spawned_at, created_at, updated_at, revision`
+
+// clientNameConstraint is the case-insensitive unique index of the clients' names
+const clientNameConstraint = "clients_name_unique"
+
+// uniqueViolation is the code of the error returned by Postgres on a duplicate key
+const uniqueViolation = "23505"
+
+// anyRevision lets the client be changed whatever its current revision is
+const anyRevision = 0
+
+// changesChannel is notified by the triggers on changes of the desired state of the workloads
+const changesChannel = "sync_algo_changes"
+
 type Storage struct {
 	pool *pgxpool.Pool
 }
@@ -32,29 +49,29 @@ func New(cfg *config.Storage) (*Storage, error) {
 		cfg.Database,
 	))
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
 	err = pool.Ping(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
 	db := stdlib.OpenDB(*pool.Config().ConnConfig)
 
 	driver, err := postgres.WithInstance(db, &postgres.Config{})
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance("file://./migrations", "postgres", driver)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
 	err = m.Up()
 	if err != nil && err != migrate.ErrNoChange {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
 	return &Storage{pool: pool}, nil
@@ -65,7 +82,7 @@ func (s *Storage) CreateClient(ctx context.Context, clientInfo *models.Client) (
 
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 	defer func() {
 		if p := recover(); p != nil {
@@ -74,72 +91,65 @@ func (s *Storage) CreateClient(ctx context.Context, clientInfo *models.Client) (
 		}
 	}()
 
-	query := `
-        INSERT INTO clients (name, version, image, cpu, memory, priority, need_restart, spawned_at, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-        RETURNING id, name, version, image, cpu, memory, priority, need_restart, spawned_at, created_at, updated_at
-    `
+	client, err := insertClient(ctx, tx, clientInfo)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, wrap(op, err)
+	}
 
-	row := tx.QueryRow(ctx, query,
-		clientInfo.ClientName,
-		clientInfo.Version,
-		clientInfo.Image,
-		clientInfo.CPU,
-		clientInfo.Memory,
-		clientInfo.Priority,
-		clientInfo.NeedRestart,
-		clientInfo.SpawnedAt,
-		clientInfo.CreatedAt,
-		clientInfo.UpdatedAt,
-	)
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
 
-	var client models.Client
-	err = row.Scan(&client.ID, &client.ClientName, &client.Version, &client.Image, &client.CPU, &client.Memory, &client.Priority, &client.NeedRestart, &client.SpawnedAt, &client.CreatedAt, &client.UpdatedAt)
+	return client, nil
+}
+
+func (s *Storage) UpdateClient(ctx context.Context, clientInfo *models.Client) (*models.Client, error) {
+	const op = "storage.postgres.UpdateClient"
+
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		defer tx.Rollback(ctx)
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
 
-	_, err = tx.Exec(ctx, "INSERT INTO algorithm_statuses (client_id) VALUES ($1)", client.ID)
+	client, err := updateClient(ctx, tx, clientInfo)
 	if err != nil {
-		_ = tx.Rollback(ctx)
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
-	return &client, nil
+	return client, nil
 }
 
-func (s *Storage) UpdateClient(ctx context.Context, clientInfo *models.Client) error {
-	const op = "storage.postgres.UpdateClient"
+// PatchClient changes only the client's fields set in the patch
+func (s *Storage) PatchClient(ctx context.Context, patch *models.ClientPatch) (*models.Client, error) {
+	const op = "storage.postgres.PatchClient"
 
-	// Формируем окончательный запрос
-	q := `
-		UPDATE clients 
-		SET name = $1, version = $2, image = $3, cpu = $4, memory = $5, priority = $6, need_restart = $7, updated_at = $8
-		WHERE id = $9 
-	`
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
 
-	_, err := s.pool.Exec(ctx, q,
-		clientInfo.ClientName,
-		clientInfo.Version,
-		clientInfo.Image,
-		clientInfo.CPU,
-		clientInfo.Memory,
-		clientInfo.Priority,
-		clientInfo.NeedRestart,
-		time.Now(),
-		clientInfo.ID,
-	)
+	client, err := patchClient(ctx, tx, patch)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
-	return nil
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return client, nil
 }
 
 func (s *Storage) RemoveClient(ctx context.Context, id int) error {
@@ -147,7 +157,7 @@ func (s *Storage) RemoveClient(ctx context.Context, id int) error {
 
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return wrap(op, err)
 	}
 	defer func() {
 		if p := recover(); p != nil {
@@ -156,77 +166,524 @@ func (s *Storage) RemoveClient(ctx context.Context, id int) error {
 		}
 	}()
 
-	// Удаление из algorithm_statuses
-	_, err = tx.Exec(ctx, `DELETE FROM algorithm_statuses WHERE client_id = $1`, id)
+	before, err := lockClient(ctx, tx, int64(id), anyRevision)
 	if err != nil {
 		defer tx.Rollback(ctx)
-		return fmt.Errorf("%s: %w", op, err)
+		return wrap(op, err)
 	}
 
-	// Удаление из clients
-	ct, err := tx.Exec(ctx, `DELETE FROM clients WHERE id = $1`, id)
+	// Удаление из clients, алгоритмы клиента удаляются каскадно
+	_, err = tx.Exec(ctx, `DELETE FROM clients WHERE id = $1`, id)
 	if err != nil {
 		defer tx.Rollback(ctx)
-		return fmt.Errorf("%s: %w", op, err)
+		return wrap(op, err)
 	}
 
-	if ct.RowsAffected() == 0 {
+	err = recordEvent(ctx, tx, models.AuditClientDelete, before.ID, before, nil)
+	if err != nil {
 		defer tx.Rollback(ctx)
-		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		return wrap(op, err)
 	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
-		return fmt.Errorf("%s: %w", op, err)
+		return wrap(op, err)
 	}
 
 	return nil
 }
 
-func (s *Storage) UpdateStatuses(ctx context.Context, fields []string, values []interface{}) (*models.AlgoStatuses, error) {
-	const op = "storage.postgres.UpdateStatuses"
+func (s *Storage) FetchClients(ctx context.Context) ([]models.Client, error) {
+	const op = "storage.postgres.FetchClients"
 
-	// Объединяем поля в строку
-	f := strings.Join(fields, ", ")
+	rows, err := s.pool.Query(ctx, `SELECT `+clientColumns+` FROM clients ORDER BY id`)
+	if err != nil {
+		return nil, wrap(op, err)
+	}
+	defer rows.Close()
 
-	q := fmt.Sprintf("UPDATE algorithm_statuses SET %s WHERE id = $%d RETURNING client_id, vwap, twap, hft", f, len(fields)+1)
+	var clients []models.Client
+	for rows.Next() {
+		var client models.Client
+		if err := scanClient(rows, &client); err != nil {
+			return nil, wrap(op, err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, wrap(op, err)
+	}
+
+	return clients, nil
+}
 
-	row := s.pool.QueryRow(ctx, q, values...)
+// FetchClient returns the client with the given ID
+func (s *Storage) FetchClient(ctx context.Context, id int) (*models.Client, error) {
+	const op = "storage.postgres.FetchClient"
 
-	var algoStatuses models.AlgoStatuses
-	err := row.Scan(&algoStatuses.ClientID, &algoStatuses.VWAP, &algoStatuses.TWAP, &algoStatuses.HFT)
+	row := s.pool.QueryRow(ctx, `SELECT `+clientColumns+` FROM clients WHERE id = $1`, id)
+
+	var client models.Client
+	err := scanClient(row, &client)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return nil, wrap(op, err)
 	}
 
-	return &algoStatuses, nil
+	return &client, nil
 }
 
-func (s *Storage) FetchCurrentStatuses(ctx context.Context) ([]models.AlgoStatuses, error) {
-	const op = "storage.postgres.FetchCurrentStatuses"
+// CompleteRestart clears the client's restart flag and error, and bumps its spawn time.
+// The revision is the one observed when the restart started, if the client was changed since then,
+// e.g. the restart was requested again, the flag is left set and storage.ErrStaleRevision is returned
+func (s *Storage) CompleteRestart(ctx context.Context, clientID int, revision int) error {
+	const op = "storage.postgres.CompleteRestart"
 
-	rows, err := s.pool.Query(ctx, "SELECT client_id, vwap, twap, hft FROM algorithm_statuses")
+	tx, err := s.pool.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+		return wrap(op, err)
 	}
-	defer rows.Close()
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
 
-	var statuses []models.AlgoStatuses
-	for rows.Next() {
-		var status models.AlgoStatuses
-		if err := rows.Scan(&status.ClientID, &status.VWAP, &status.TWAP, &status.HFT); err != nil {
-			return nil, fmt.Errorf("%s: %w", op, err)
+	before, err := lockClient(ctx, tx, int64(clientID), revision)
+	if err != nil {
+		return wrap(op, err)
+	}
+
+	row := tx.QueryRow(ctx, `
+		UPDATE clients
+		SET need_restart = FALSE, restart_error = '', spawned_at = $1
+		WHERE id = $2
+		RETURNING `+clientColumns, time.Now(), clientID)
+
+	var after models.Client
+	if err := scanClient(row, &after); err != nil {
+		return wrap(op, err)
+	}
+
+	// The context of the scheduler carries no actor, so the event is recorded as the system's one
+	if err := recordEvent(ctx, tx, models.AuditClientRestart, after.ID, before, &after); err != nil {
+		return wrap(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return wrap(op, err)
+	}
+
+	return nil
+}
+
+// FailRestart records the reason of the client's failed restart, leaving the restart flag set.
+// The unchanged reason is neither written nor audited again
+func (s *Storage) FailRestart(ctx context.Context, clientID int, reason string) error {
+	const op = "storage.postgres.FailRestart"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return wrap(op, err)
+	}
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
+
+	before, err := lockClient(ctx, tx, int64(clientID), anyRevision)
+	if err != nil {
+		return wrap(op, err)
+	}
+
+	// A restart failing the same way on every sync is recorded once
+	if before.RestartError == reason {
+		return nil
+	}
+
+	row := tx.QueryRow(ctx, `UPDATE clients SET restart_error = $1 WHERE id = $2 RETURNING `+clientColumns, reason, clientID)
+
+	var after models.Client
+	if err := scanClient(row, &after); err != nil {
+		return wrap(op, err)
+	}
+
+	if err := recordEvent(ctx, tx, models.AuditClientRestartFail, after.ID, before, &after); err != nil {
+		return wrap(op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return wrap(op, err)
+	}
+
+	return nil
+}
+
+// UpdateWorkloadPhase records the phase of the pod running the client's algorithm.
+// Pods of the removed clients are skipped, as well as deletion of a pod replaced by a newer one
+// and deletion of a workload the scheduler has already forgotten.
+// The changes of the phase are audited, the updates keeping it aren't.
+func (s *Storage) UpdateWorkloadPhase(ctx context.Context, instance models.Instance) error {
+	const op = "storage.postgres.UpdateWorkloadPhase"
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return wrap(op, err)
+	}
+	// Does nothing after commit
+	defer tx.Rollback(ctx)
+
+	before, err := lockWorkload(ctx, tx, instance.ClientID, instance.Algorithm)
+	if err != nil {
+		return wrap(op, err)
+	}
+
+	row := tx.QueryRow(ctx, `
+		INSERT INTO workloads (client_id, algorithm, pod_name, phase, reason, restart_count, last_transition_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $7
+		WHERE EXISTS (SELECT 1 FROM clients WHERE id = $1)
+			AND ($4 <> $8 OR EXISTS (SELECT 1 FROM workloads WHERE client_id = $1 AND algorithm = $2))
+		ON CONFLICT (client_id, algorithm) DO UPDATE
+		SET pod_name = EXCLUDED.pod_name,
+			phase = EXCLUDED.phase,
+			reason = EXCLUDED.reason,
+			restart_count = EXCLUDED.restart_count,
+			last_transition_at = CASE
+				WHEN workloads.phase = EXCLUDED.phase THEN workloads.last_transition_at
+				ELSE EXCLUDED.last_transition_at
+			END,
+			updated_at = EXCLUDED.updated_at
+		WHERE EXCLUDED.phase <> $8 OR workloads.pod_name = EXCLUDED.pod_name
+		RETURNING pod_name, phase, reason, restart_count
+	`, instance.ClientID, instance.Algorithm, instance.Name, instance.Phase, instance.Reason, instance.RestartCount,
+		time.Now(), models.PhaseDeleted)
+
+	var after models.WorkloadState
+	err = row.Scan(&after.Pod, &after.Phase, &after.Reason, &after.RestartCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The phase was skipped
+		return nil
+	}
+	if err != nil {
+		return wrap(op, err)
+	}
+
+	if before == nil || before.Phase != after.Phase {
+		if err := recordEvent(ctx, tx, models.AuditWorkloadPhase, int64(instance.ClientID), before, &after); err != nil {
+			return wrap(op, err)
 		}
-		statuses = append(statuses, status)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: %w", op, err)
+	if err := tx.Commit(ctx); err != nil {
+		return wrap(op, err)
+	}
+
+	return nil
+}
+
+// SaveWorkload records the workload successfully deployed by the scheduler with the image it runs, clearing the last error.
+// Workloads of the removed clients are skipped.
+func (s *Storage) SaveWorkload(ctx context.Context, workload models.Workload) error {
+	const op = "storage.postgres.SaveWorkload"
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO workloads (client_id, algorithm, pod_name, image, version, last_error, last_transition_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, '', $6, $6
+		WHERE EXISTS (SELECT 1 FROM clients WHERE id = $1)
+		ON CONFLICT (client_id, algorithm) DO UPDATE
+		SET image = EXCLUDED.image,
+			version = EXCLUDED.version,
+			last_error = '',
+			last_transition_at = EXCLUDED.last_transition_at,
+			updated_at = EXCLUDED.updated_at
+	`, workload.ClientID, workload.Algorithm, workload.Name, workload.Image, workload.Version, time.Now())
+	if err != nil {
+		return wrap(op, err)
+	}
+
+	return nil
+}
+
+// FailWorkload records the error of the scheduler's last action on the workload,
+// keeping the previously deployed image and version
+func (s *Storage) FailWorkload(ctx context.Context, workload models.Workload, reason string) error {
+	const op = "storage.postgres.FailWorkload"
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO workloads (client_id, algorithm, pod_name, last_error, updated_at)
+		SELECT $1, $2, $3, $4, $5
+		WHERE EXISTS (SELECT 1 FROM clients WHERE id = $1)
+		ON CONFLICT (client_id, algorithm) DO UPDATE
+		SET last_error = EXCLUDED.last_error, updated_at = EXCLUDED.updated_at
+	`, workload.ClientID, workload.Algorithm, workload.Name, reason, time.Now())
+	if err != nil {
+		return wrap(op, err)
+	}
+
+	return nil
+}
+
+// DeleteWorkload forgets the workload removed from the cluster
+func (s *Storage) DeleteWorkload(ctx context.Context, clientID int, algorithm string) error {
+	const op = "storage.postgres.DeleteWorkload"
+
+	_, err := s.pool.Exec(ctx, `DELETE FROM workloads WHERE client_id = $1 AND algorithm = $2`, clientID, algorithm)
+	if err != nil {
+		return wrap(op, err)
 	}
 
-	return statuses, nil
+	return nil
+}
+
+// Listen sends to notify whenever the desired state of the workloads changes.
+// It notifies once right after subscribing, so the changes made while not subscribed are not lost,
+// and blocks until the context is cancelled or the connection fails.
+func (s *Storage) Listen(ctx context.Context, notify chan<- struct{}) error {
+	const op = "storage.postgres.Listen"
+
+	pooled, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return wrap(op, err)
+	}
+
+	// The connection stays subscribed, so it is closed instead of being returned to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	_, err = conn.Exec(ctx, "LISTEN "+changesChannel)
+	if err != nil {
+		return wrap(op, err)
+	}
+
+	for {
+		// Changes coalesce while the previous notification is not handled yet
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+
+		_, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return wrap(op, err)
+		}
+	}
 }
 
 func (s *Storage) Close() {
 	s.pool.Close()
 }
+
+// scanClient reads a row selected with clientColumns
+func scanClient(row pgx.Row, client *models.Client) error {
+	return row.Scan(
+		&client.ID,
+		&client.ClientName,
+		&client.Version,
+		&client.Image,
+		&client.CPU,
+		&client.Memory,
+		&client.Priority,
+		&client.NeedRestart,
+		&client.RestartError,
+		&client.SpawnedAt,
+		&client.CreatedAt,
+		&client.UpdatedAt,
+		&client.Revision,
+	)
+}
+
+// querier is implemented by both the pool and the transactions
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// insertClient creates the client within the transaction, all of its algorithms are disabled
+// The timestamps are set by the column defaults, the ones from the request are ignored
+func insertClient(ctx context.Context, tx pgx.Tx, clientInfo *models.Client) (*models.Client, error) {
+	query := `
+        INSERT INTO clients (name, version, image, cpu, memory, priority, need_restart)
+        VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, FALSE))
+        RETURNING ` + clientColumns
+
+	row := tx.QueryRow(ctx, query,
+		clientInfo.ClientName,
+		clientInfo.Version,
+		clientInfo.Image,
+		clientInfo.CPU,
+		clientInfo.Memory,
+		clientInfo.Priority,
+		clientInfo.NeedRestart,
+	)
+
+	var client models.Client
+	if err := scanClient(row, &client); err != nil {
+		return nil, nameConflict(err)
+	}
+
+	if err := recordEvent(ctx, tx, models.AuditClientCreate, client.ID, nil, &client); err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// updateClient replaces the client's fields within the transaction, the non-zero revision must be the current one
+func updateClient(ctx context.Context, q querier, clientInfo *models.Client) (*models.Client, error) {
+	before, err := lockClient(ctx, q, clientInfo.ID, clientInfo.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	// Формируем окончательный запрос
+	query := `
+		UPDATE clients
+		SET name = $1, version = $2, image = $3, cpu = $4, memory = $5, priority = $6, need_restart = COALESCE($7, FALSE), updated_at = $8,
+			revision = revision + 1
+		WHERE id = $9
+		RETURNING ` + clientColumns
+
+	row := q.QueryRow(ctx, query,
+		clientInfo.ClientName,
+		clientInfo.Version,
+		clientInfo.Image,
+		clientInfo.CPU,
+		clientInfo.Memory,
+		clientInfo.Priority,
+		clientInfo.NeedRestart,
+		time.Now(),
+		clientInfo.ID,
+	)
+
+	var client models.Client
+	if err := scanClient(row, &client); err != nil {
+		return nil, nameConflict(err)
+	}
+
+	if err := recordEvent(ctx, q, models.AuditClientUpdate, client.ID, before, &client); err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// patchClient changes the client's fields set in the patch within the transaction,
+// the non-zero revision must be the current one
+func patchClient(ctx context.Context, q querier, patch *models.ClientPatch) (*models.Client, error) {
+	before, err := lockClient(ctx, q, patch.ID, patch.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	// NULL parameters keep the columns as they are
+	query := `
+		UPDATE clients
+		SET name = COALESCE($1, name), version = COALESCE($2, version), image = COALESCE($3, image),
+			cpu = COALESCE($4, cpu), memory = COALESCE($5, memory), priority = COALESCE($6, priority),
+			need_restart = COALESCE($7, need_restart), updated_at = $8, revision = revision + 1
+		WHERE id = $9
+		RETURNING ` + clientColumns
+
+	row := q.QueryRow(ctx, query,
+		patch.ClientName,
+		patch.Version,
+		patch.Image,
+		patch.CPU,
+		patch.Memory,
+		patch.Priority,
+		patch.NeedRestart,
+		time.Now(),
+		patch.ID,
+	)
+
+	var client models.Client
+	if err := scanClient(row, &client); err != nil {
+		return nil, nameConflict(err)
+	}
+
+	if err := recordEvent(ctx, q, models.AuditClientUpdate, client.ID, before, &client); err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// lockClient locks the client's row until the end of the transaction and returns the client as it was,
+// the non-zero revision must be the current one
+func lockClient(ctx context.Context, q querier, id int64, revision int) (*models.Client, error) {
+	row := q.QueryRow(ctx, `SELECT `+clientColumns+` FROM clients WHERE id = $1 FOR UPDATE`, id)
+
+	var client models.Client
+	err := scanClient(row, &client)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, storage.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if revision != anyRevision && client.Revision != revision {
+		return nil, storage.ErrStaleRevision
+	}
+
+	return &client, nil
+}
+
+// lockWorkload selects the phase of the client's algorithm workload for update, nil if there is no workload
+func lockWorkload(ctx context.Context, q querier, clientID int, algorithm string) (*models.WorkloadState, error) {
+	row := q.QueryRow(ctx, `
+		SELECT pod_name, phase, reason, restart_count
+		FROM workloads
+		WHERE client_id = $1 AND algorithm = $2
+		FOR UPDATE
+	`, clientID, algorithm)
+
+	var state models.WorkloadState
+	err := row.Scan(&state.Pod, &state.Phase, &state.Reason, &state.RestartCount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// nameConflict translates the violation of the unique client's name into storage.ErrExists
+func nameConflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == clientNameConstraint {
+		return storage.ErrExists
+	}
+
+	return err
+}
+
+// wrap annotates the error with the operation, marking the failures to reach the database with storage.ErrUnavailable
+func wrap(op string, err error) error {
+	if isUnavailable(err) {
+		return fmt.Errorf("%s: %w: %w", op, storage.ErrUnavailable, err)
+	}
+
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// isUnavailable reports whether the error is caused by the database being unreachable or overloaded,
+// rather than by the request itself
+func isUnavailable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) || pgconn.Timeout(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Connection exceptions, insufficient resources and operator intervention, e.g. shutdown
+		switch pgErr.Code[:2] {
+		case "08", "53", "57":
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,8 +1,28 @@
 package storage
 
-import "errors"
+import (
+	"errors"
+
+	"sync-algo/internal/lib/apperr"
+)
 
 var (
-	ErrUserNotFound = errors.New("client not found")
-	ErrExists       = errors.New("client already exists")
+	ErrUserNotFound = apperr.New(apperr.KindNotFound, "client_not_found", "client not found")
+	ErrExists       = apperr.New(apperr.KindConflict, "client_exists", "client already exists")
+	// ErrStaleRevision is returned when the client was changed since the revision the update is based on
+	ErrStaleRevision = apperr.New(apperr.KindPrecondition, "stale_revision", "client was modified by another request")
+	// ErrInvalidCursor is returned when the page cursor is malformed or was issued for another order
+	ErrInvalidCursor = apperr.BadRequest("invalid_cursor", "invalid cursor")
+	// ErrUnknownAlgorithm is returned when the algorithm to toggle is missing in the catalog
+	ErrUnknownAlgorithm = apperr.Validation("unknown_algorithm", "unknown algorithm")
+	// ErrAlgorithmNotFound is returned when the algorithm addressed by the request is missing in the catalog
+	ErrAlgorithmNotFound = apperr.New(apperr.KindNotFound, "algorithm_not_found", "algorithm not found")
+	// ErrAPIKeyNotFound is returned when the API key is unknown or revoked
+	ErrAPIKeyNotFound = apperr.New(apperr.KindNotFound, "api_key_not_found", "api key not found")
+	// ErrRoleNotAssigned is returned when the authenticated caller has no role
+	ErrRoleNotAssigned = apperr.New(apperr.KindForbidden, "no_role", "no role assigned")
+	// ErrUnavailable is returned when the database can't be reached
+	ErrUnavailable = apperr.New(apperr.KindUnavailable, "storage_unavailable", "storage unavailable")
+	// ErrBatchAborted is returned when an item of the atomic batch fails and the whole batch is rolled back
+	ErrBatchAborted = errors.New("batch rolled back")
 )
@@ -0,0 +1 @@
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS restart_error TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS clients_notify_changes ON clients;
+DROP TRIGGER IF EXISTS algorithm_statuses_notify_changes ON algorithm_statuses;
+DROP FUNCTION IF EXISTS notify_sync_algo_changes();
@@ -0,0 +1,26 @@
+-- Notifies the scheduler about changes of the desired state of the workloads.
+-- The payload is constant, so changes made in a single transaction are delivered as one notification.
+CREATE OR REPLACE FUNCTION notify_sync_algo_changes() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('sync_algo_changes', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS algorithm_statuses_notify_changes ON algorithm_statuses;
+CREATE TRIGGER algorithm_statuses_notify_changes
+    AFTER INSERT OR UPDATE OR DELETE ON algorithm_statuses
+    FOR EACH STATEMENT EXECUTE PROCEDURE notify_sync_algo_changes();
+
+-- Only the changes affecting the workloads are notified, so the scheduler's own
+-- restart bookkeeping does not trigger another sync
+DROP TRIGGER IF EXISTS clients_notify_changes ON clients;
+CREATE TRIGGER clients_notify_changes
+    AFTER UPDATE ON clients
+    FOR EACH ROW
+    WHEN (
+        (OLD.version, OLD.image, OLD.cpu, OLD.memory, OLD.priority)
+            IS DISTINCT FROM (NEW.version, NEW.image, NEW.cpu, NEW.memory, NEW.priority)
+        OR (NEW.need_restart AND NOT COALESCE(OLD.need_restart, FALSE))
+    )
+    EXECUTE PROCEDURE notify_sync_algo_changes();
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS workloads (
+    client_id INT NOT NULL REFERENCES clients (id) ON DELETE CASCADE,
+    algorithm VARCHAR(50) NOT NULL,
+    pod_name VARCHAR(255) NOT NULL DEFAULT '',
+    phase VARCHAR(50) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (client_id, algorithm)
+);
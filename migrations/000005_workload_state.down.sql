@@ -0,0 +1,6 @@
+ALTER TABLE workloads
+    DROP COLUMN IF EXISTS restart_count,
+    DROP COLUMN IF EXISTS last_transition_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS image;
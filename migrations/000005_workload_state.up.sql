@@ -0,0 +1,6 @@
+ALTER TABLE workloads
+    ADD COLUMN IF NOT EXISTS image VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS last_error TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_transition_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS restart_count INT NOT NULL DEFAULT 0;
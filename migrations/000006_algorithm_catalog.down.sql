@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS algorithm_statuses (
+    id SERIAL PRIMARY KEY,
+    client_id INT REFERENCES clients(id) UNIQUE,
+    vwap BOOLEAN DEFAULT FALSE,
+    twap BOOLEAN DEFAULT FALSE,
+    hft BOOLEAN DEFAULT FALSE,
+    FOREIGN KEY (client_id) REFERENCES clients (id) ON DELETE CASCADE
+);
+
+-- Only the algorithms which had columns survive
+INSERT INTO algorithm_statuses (client_id, vwap, twap, hft)
+SELECT
+    c.id,
+    COALESCE(BOOL_OR(ca.enabled) FILTER (WHERE ca.algorithm = 'vwap'), FALSE),
+    COALESCE(BOOL_OR(ca.enabled) FILTER (WHERE ca.algorithm = 'twap'), FALSE),
+    COALESCE(BOOL_OR(ca.enabled) FILTER (WHERE ca.algorithm = 'hft'), FALSE)
+FROM clients c
+LEFT JOIN client_algorithms ca ON ca.client_id = c.id
+GROUP BY c.id
+ON CONFLICT DO NOTHING;
+
+CREATE TRIGGER algorithm_statuses_notify_changes
+    AFTER INSERT OR UPDATE OR DELETE ON algorithm_statuses
+    FOR EACH STATEMENT EXECUTE PROCEDURE notify_sync_algo_changes();
+
+DROP TABLE IF EXISTS client_algorithms;
+DROP TABLE IF EXISTS algorithms;
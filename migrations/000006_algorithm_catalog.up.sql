@@ -0,0 +1,38 @@
+-- Catalog of the algorithms which can be enabled for the clients
+CREATE TABLE IF NOT EXISTS algorithms (
+    name VARCHAR(50) PRIMARY KEY,
+    image VARCHAR(255) NOT NULL DEFAULT '',
+    cpu VARCHAR(50) NOT NULL DEFAULT '',
+    memory VARCHAR(50) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+INSERT INTO algorithms (name) VALUES ('vwap'), ('twap'), ('hft') ON CONFLICT DO NOTHING;
+
+-- Algorithms enabled for the clients, a missing row means the algorithm is disabled
+CREATE TABLE IF NOT EXISTS client_algorithms (
+    client_id INT NOT NULL REFERENCES clients (id) ON DELETE CASCADE,
+    algorithm VARCHAR(50) NOT NULL REFERENCES algorithms (name) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (client_id, algorithm)
+);
+
+INSERT INTO client_algorithms (client_id, algorithm, enabled)
+SELECT client_id, 'vwap', COALESCE(vwap, FALSE) FROM algorithm_statuses WHERE client_id IS NOT NULL
+UNION ALL
+SELECT client_id, 'twap', COALESCE(twap, FALSE) FROM algorithm_statuses WHERE client_id IS NOT NULL
+UNION ALL
+SELECT client_id, 'hft', COALESCE(hft, FALSE) FROM algorithm_statuses WHERE client_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+DROP TABLE IF EXISTS algorithm_statuses;
+
+DROP TRIGGER IF EXISTS client_algorithms_notify_changes ON client_algorithms;
+CREATE TRIGGER client_algorithms_notify_changes
+    AFTER INSERT OR UPDATE OR DELETE ON client_algorithms
+    FOR EACH STATEMENT EXECUTE PROCEDURE notify_sync_algo_changes();
+
+DROP TRIGGER IF EXISTS algorithms_notify_changes ON algorithms;
+CREATE TRIGGER algorithms_notify_changes
+    AFTER INSERT OR UPDATE OR DELETE ON algorithms
+    FOR EACH STATEMENT EXECUTE PROCEDURE notify_sync_algo_changes();
@@ -0,0 +1,2 @@
+ALTER TABLE client_algorithms
+    DROP COLUMN IF EXISTS params;
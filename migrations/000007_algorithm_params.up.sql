@@ -0,0 +1,4 @@
+-- Parameters of the client's algorithm passed to its workload
+ALTER TABLE client_algorithms
+    ADD COLUMN IF NOT EXISTS params JSONB NOT NULL DEFAULT '{}'
+        CHECK (jsonb_typeof(params) = 'object');
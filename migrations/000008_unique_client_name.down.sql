@@ -0,0 +1 @@
+DROP INDEX IF EXISTS clients_name_unique;
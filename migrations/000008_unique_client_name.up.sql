@@ -0,0 +1,26 @@
+-- Names differing only in case are the same client's name.
+-- The duplicates created before the constraint existed are renamed, keeping the oldest client's name as is.
+-- A renamed name may be taken as well, so the suffix is extended until it's free
+DO $$
+DECLARE
+    dup RECORD;
+    candidate TEXT;
+BEGIN
+    FOR dup IN
+        SELECT c.id, c.name FROM clients c
+        WHERE EXISTS (
+            SELECT 1 FROM clients o
+            WHERE lower(o.name) = lower(c.name) AND o.id < c.id
+        )
+        ORDER BY c.id
+    LOOP
+        candidate := dup.name || ' #' || dup.id;
+        WHILE EXISTS (SELECT 1 FROM clients WHERE lower(name) = lower(candidate)) LOOP
+            candidate := candidate || ' #' || dup.id;
+        END LOOP;
+
+        UPDATE clients SET name = candidate WHERE id = dup.id;
+    END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS clients_name_unique ON clients (lower(name));
@@ -0,0 +1,3 @@
+-- The revision is bumped on every change of the client made through the API,
+-- the requests carrying a stale revision are rejected instead of overwriting the other's change
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS revision INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS client_algorithms_enabled;
+DROP INDEX IF EXISTS clients_need_restart;
+DROP INDEX IF EXISTS clients_name_trgm;
+DROP INDEX IF EXISTS clients_priority_sort;
+DROP INDEX IF EXISTS clients_created_at_sort;
+DROP INDEX IF EXISTS clients_name_sort;
+
+-- pg_trgm is kept, other objects of the database may depend on it
+ALTER TABLE clients
+    ALTER COLUMN priority DROP NOT NULL,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN need_restart DROP NOT NULL;
@@ -0,0 +1,20 @@
+-- The keyset pagination compares the sort keys as tuples, which doesn't work with NULLs
+UPDATE clients SET priority = 1.0 WHERE priority IS NULL;
+UPDATE clients SET created_at = CURRENT_TIMESTAMP WHERE created_at IS NULL;
+-- The plain comparison of the filter lets the planner use the partial index of need_restart
+UPDATE clients SET need_restart = FALSE WHERE need_restart IS NULL;
+ALTER TABLE clients
+    ALTER COLUMN priority SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN need_restart SET NOT NULL;
+
+-- Sort keys of the client listing, the ties are ordered by id
+CREATE INDEX IF NOT EXISTS clients_name_sort ON clients (lower(name), id);
+CREATE INDEX IF NOT EXISTS clients_created_at_sort ON clients (created_at, id);
+CREATE INDEX IF NOT EXISTS clients_priority_sort ON clients (priority, id);
+
+-- Filters of the client listing
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS clients_name_trgm ON clients USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS clients_need_restart ON clients (id) WHERE need_restart;
+CREATE INDEX IF NOT EXISTS client_algorithms_enabled ON client_algorithms (algorithm, client_id) WHERE enabled;
@@ -0,0 +1,6 @@
+-- Roles of the authenticated callers: jwt:<sub> of the token or apikey:<name> of the API key
+CREATE TABLE IF NOT EXISTS role_assignments (
+    subject VARCHAR(255) PRIMARY KEY CHECK (subject LIKE 'jwt:%' OR subject LIKE 'apikey:%'),
+    role VARCHAR(20) NOT NULL CHECK (role IN ('viewer', 'operator', 'admin')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);